@@ -0,0 +1,200 @@
+package admin
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/rjansen/l"
+)
+
+// Levels is the json document with the levels of a Logger and its named children
+type Levels struct {
+	Level   l.Level                `json:"level"`
+	Expires *time.Time             `json:"expires,omitempty"`
+	Loggers map[string]LoggerLevel `json:"loggers"`
+}
+
+// LoggerLevel is the json document with the level of a named Logger
+type LoggerLevel struct {
+	Level     l.Level    `json:"level"`
+	Inherited bool       `json:"inherited"`
+	Expires   *time.Time `json:"expires,omitempty"`
+}
+
+// LevelChange is the json document accepted to change the level of a Logger.
+// An empty Logger is the root, an empty Level resets a named Logger to its inherited level
+// and a TTL, in time.ParseDuration format, reverts the change once it expires.
+type LevelChange struct {
+	Logger string `json:"logger"`
+	Level  string `json:"level"`
+	TTL    string `json:"ttl,omitempty"`
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+type timer interface {
+	Stop() bool
+}
+
+type revert struct {
+	levels     *l.LevelRegistry
+	level      l.Level
+	overridden bool
+	expires    time.Time
+	timer      timer
+}
+
+type levelHandler struct {
+	levels    func() *l.LevelRegistry
+	mutex     sync.Mutex
+	reverts   map[string]*revert
+	now       func() time.Time
+	afterFunc func(time.Duration, func()) timer
+}
+
+func newLevelHandler(levels func() *l.LevelRegistry) *levelHandler {
+	return &levelHandler{
+		levels:  levels,
+		reverts: make(map[string]*revert),
+		now:     time.Now,
+		afterFunc: func(ttl time.Duration, f func()) timer {
+			return time.AfterFunc(ttl, f)
+		},
+	}
+}
+
+// NewLevelHandler creates an http.Handler which reports the levels of the registry as json on GET and changes them on PUT or POST
+func NewLevelHandler(levels *l.LevelRegistry) http.Handler {
+	return newLevelHandler(func() *l.LevelRegistry { return levels })
+}
+
+// NewHandler creates the level http.Handler over the current l.LoggerDefault
+func NewHandler() http.Handler {
+	return newLevelHandler(func() *l.LevelRegistry { return l.LevelsOf(l.LoggerDefault) })
+}
+
+func (handler *levelHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	levels := handler.levels()
+	if levels == nil {
+		writeJSON(w, http.StatusNotImplemented, errorResponse{Error: "err_unsupported_logger{Message='Logger does not expose its levels'}"})
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+		writeJSON(w, http.StatusOK, handler.snapshot(levels))
+	case http.MethodPut, http.MethodPost:
+		var change LevelChange
+		if err := json.NewDecoder(r.Body).Decode(&change); err != nil {
+			writeJSON(w, http.StatusBadRequest, errorResponse{Error: fmt.Sprintf("err_invalid_body{Message=%q}", err.Error())})
+			return
+		}
+		if err := handler.change(levels, change); err != nil {
+			writeJSON(w, http.StatusBadRequest, errorResponse{Error: err.Error()})
+			return
+		}
+		writeJSON(w, http.StatusOK, handler.snapshot(levels))
+	default:
+		w.Header().Set("Allow", "GET, PUT, POST")
+		writeJSON(w, http.StatusMethodNotAllowed, errorResponse{Error: fmt.Sprintf("err_method_not_allowed{Method=%q}", r.Method)})
+	}
+}
+
+func (handler *levelHandler) change(levels *l.LevelRegistry, change LevelChange) error {
+	var ttl time.Duration
+	if change.TTL != "" {
+		var err error
+		if ttl, err = time.ParseDuration(change.TTL); err != nil || ttl <= 0 {
+			return fmt.Errorf("err_invalid_ttl{TTL=%q}", change.TTL)
+		}
+	}
+
+	handler.mutex.Lock()
+	defer handler.mutex.Unlock()
+
+	previous, pending := handler.reverts[change.Logger]
+	if !pending || previous.levels != levels {
+		level, overridden := levels.Override(change.Logger)
+		previous = &revert{levels: levels, level: level, overridden: overridden}
+	}
+
+	if err := apply(levels, change.Logger, change.Level); err != nil {
+		return err
+	}
+
+	if pending {
+		handler.reverts[change.Logger].timer.Stop()
+		delete(handler.reverts, change.Logger)
+	}
+	if ttl > 0 {
+		previous.expires = handler.now().Add(ttl)
+		previous.timer = handler.afterFunc(ttl, handler.revertFunc(change.Logger, previous))
+		handler.reverts[change.Logger] = previous
+	}
+	return nil
+}
+
+func (handler *levelHandler) revertFunc(name string, previous *revert) func() {
+	return func() {
+		handler.mutex.Lock()
+		defer handler.mutex.Unlock()
+		if handler.reverts[name] != previous {
+			return
+		}
+		delete(handler.reverts, name)
+		if previous.overridden {
+			_ = previous.levels.SetLevel(name, previous.level)
+		} else {
+			_ = previous.levels.ResetLevel(name)
+		}
+	}
+}
+
+func apply(levels *l.LevelRegistry, name, value string) error {
+	if value == "" {
+		return levels.ResetLevel(name)
+	}
+	level, err := l.ParseLevel(value)
+	if err != nil {
+		return err
+	}
+	return levels.SetLevel(name, level)
+}
+
+func (handler *levelHandler) snapshot(levels *l.LevelRegistry) Levels {
+	handler.mutex.Lock()
+	defer handler.mutex.Unlock()
+
+	snapshot := Levels{
+		Level:   levels.Level(""),
+		Expires: handler.expires(levels, ""),
+		Loggers: make(map[string]LoggerLevel),
+	}
+	for _, name := range levels.Names() {
+		_, overridden := levels.Override(name)
+		snapshot.Loggers[name] = LoggerLevel{
+			Level:     levels.Level(name),
+			Inherited: !overridden,
+			Expires:   handler.expires(levels, name),
+		}
+	}
+	return snapshot
+}
+
+func (handler *levelHandler) expires(levels *l.LevelRegistry, name string) *time.Time {
+	if pending, exists := handler.reverts[name]; exists && pending.levels == levels {
+		expires := pending.expires
+		return &expires
+	}
+	return nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
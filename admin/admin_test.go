@@ -0,0 +1,242 @@
+package admin
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rjansen/l"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeTimer struct {
+	ttl     time.Duration
+	f       func()
+	stopped bool
+}
+
+func (timer *fakeTimer) Stop() bool {
+	timer.stopped = true
+	return true
+}
+
+type fakeClock struct {
+	now    time.Time
+	timers []*fakeTimer
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Date(2019, 10, 1, 12, 0, 0, 0, time.UTC)}
+}
+
+func (clock *fakeClock) Now() time.Time {
+	return clock.now
+}
+
+func (clock *fakeClock) AfterFunc(ttl time.Duration, f func()) timer {
+	timer := &fakeTimer{ttl: ttl, f: f}
+	clock.timers = append(clock.timers, timer)
+	return timer
+}
+
+func (clock *fakeClock) fire() {
+	for _, timer := range clock.timers {
+		if !timer.stopped {
+			timer.stopped = true
+			timer.f()
+		}
+	}
+}
+
+func newTestHandler(levels *l.LevelRegistry) (*levelHandler, *fakeClock) {
+	var (
+		clock   = newFakeClock()
+		handler = newLevelHandler(func() *l.LevelRegistry { return levels })
+	)
+	handler.now = clock.Now
+	handler.afterFunc = clock.AfterFunc
+	return handler, clock
+}
+
+func serve(t *testing.T, handler http.Handler, method, body string) (int, Levels) {
+	var (
+		request  = httptest.NewRequest(method, "/levels", strings.NewReader(body))
+		recorder = httptest.NewRecorder()
+		levels   Levels
+	)
+	handler.ServeHTTP(recorder, request)
+	assert.Equal(t, "application/json", recorder.Header().Get("Content-Type"), "content type")
+	if recorder.Code == http.StatusOK {
+		assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &levels), "response body")
+	}
+	return recorder.Code, levels
+}
+
+type testLevelHandler struct {
+	name     string
+	method   string
+	body     string
+	status   int
+	expected Levels
+}
+
+func TestLevelHandler(test *testing.T) {
+	scenarios := []testLevelHandler{
+		{
+			name:   "Reports the levels",
+			method: http.MethodGet,
+			status: http.StatusOK,
+			expected: Levels{
+				Level: l.INFO,
+				Loggers: map[string]LoggerLevel{
+					"api":    {Level: l.DEBUG},
+					"api.db": {Level: l.DEBUG, Inherited: true},
+				},
+			},
+		},
+		{
+			name:   "Changes the root level",
+			method: http.MethodPut,
+			body:   `{"level":"error"}`,
+			status: http.StatusOK,
+			expected: Levels{
+				Level: l.ERROR,
+				Loggers: map[string]LoggerLevel{
+					"api":    {Level: l.DEBUG},
+					"api.db": {Level: l.DEBUG, Inherited: true},
+				},
+			},
+		},
+		{
+			name:   "Changes a named logger level",
+			method: http.MethodPost,
+			body:   `{"logger":"api.db","level":"TRACE"}`,
+			status: http.StatusOK,
+			expected: Levels{
+				Level: l.INFO,
+				Loggers: map[string]LoggerLevel{
+					"api":    {Level: l.DEBUG},
+					"api.db": {Level: l.TRACE},
+				},
+			},
+		},
+		{
+			name:   "Resets a named logger level",
+			method: http.MethodPut,
+			body:   `{"logger":"api"}`,
+			status: http.StatusOK,
+			expected: Levels{
+				Level: l.INFO,
+				Loggers: map[string]LoggerLevel{
+					"api":    {Level: l.INFO, Inherited: true},
+					"api.db": {Level: l.INFO, Inherited: true},
+				},
+			},
+		},
+		{
+			name:   "Does not change an invalid level",
+			method: http.MethodPut,
+			body:   `{"logger":"api","level":"eror"}`,
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "Does not change with an invalid ttl",
+			method: http.MethodPut,
+			body:   `{"logger":"api","level":"info","ttl":"forever"}`,
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "Does not change with an invalid body",
+			method: http.MethodPut,
+			body:   `level=info`,
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "Does not accept other methods",
+			method: http.MethodDelete,
+			status: http.StatusMethodNotAllowed,
+		},
+	}
+
+	for index, scenario := range scenarios {
+		test.Run(
+			fmt.Sprintf("[%d]-%s", index, scenario.name),
+			func(t *testing.T) {
+				levels := l.NewLevelRegistry(l.NewAtomicLevel(l.INFO))
+				assert.NoError(t, levels.SetLevel("api", l.DEBUG), "SetLevel error")
+				levels.Controller("api.db")
+
+				status, response := serve(t, NewLevelHandler(levels), scenario.method, scenario.body)
+				assert.Equal(t, scenario.status, status, "response status")
+				if scenario.status == http.StatusOK {
+					assert.Equal(t, scenario.expected, response, "response levels")
+				}
+			},
+		)
+	}
+}
+
+func TestLevelHandlerTTL(t *testing.T) {
+	var (
+		levels         = l.NewLevelRegistry(l.NewAtomicLevel(l.INFO))
+		handler, clock = newTestHandler(levels)
+		expires        = clock.now.Add(5 * time.Minute)
+	)
+
+	status, response := serve(t, handler, http.MethodPut, `{"logger":"api","level":"debug","ttl":"5m"}`)
+	assert.Equal(t, http.StatusOK, status, "response status")
+	assert.Equal(t, LoggerLevel{Level: l.DEBUG, Expires: &expires}, response.Loggers["api"], "api level")
+
+	status, response = serve(t, handler, http.MethodPut, `{"logger":"api","level":"trace","ttl":"1m"}`)
+	assert.Equal(t, http.StatusOK, status, "response status")
+	assert.Equal(t, l.TRACE, response.Loggers["api"].Level, "api level")
+	assert.True(t, clock.timers[0].stopped, "first timer stopped")
+
+	status, response = serve(t, handler, http.MethodPut, `{"level":"error","ttl":"1h"}`)
+	assert.Equal(t, http.StatusOK, status, "response status")
+	assert.Equal(t, l.ERROR, response.Level, "root level")
+
+	clock.fire()
+	status, response = serve(t, handler, http.MethodGet, "")
+	assert.Equal(t, http.StatusOK, status, "response status")
+	assert.Equal(t, Levels{
+		Level: l.INFO,
+		Loggers: map[string]LoggerLevel{
+			"api": {Level: l.INFO, Inherited: true},
+		},
+	}, response, "reverted levels")
+}
+
+func TestLevelHandlerPermanentChange(t *testing.T) {
+	var (
+		levels         = l.NewLevelRegistry(l.NewAtomicLevel(l.INFO))
+		handler, clock = newTestHandler(levels)
+	)
+	serve(t, handler, http.MethodPut, `{"logger":"api","level":"debug","ttl":"5m"}`)
+	serve(t, handler, http.MethodPut, `{"logger":"api","level":"warn"}`)
+	clock.fire()
+	assert.Equal(t, l.WARN, levels.Level("api"), "api level")
+}
+
+func TestHandler(t *testing.T) {
+	defaultLogger := l.LoggerDefault
+	defer l.SetLoggerDefault(defaultLogger)
+
+	logger := l.NewWithLevel(l.NewTeeDriver(), l.NewAtomicLevel(l.WARN))
+	assert.NoError(t, l.SetLoggerDefault(logger))
+	status, response := serve(t, NewHandler(), http.MethodGet, "")
+	assert.Equal(t, http.StatusOK, status, "response status")
+	assert.Equal(t, l.WARN, response.Level, "default level")
+
+	assert.NoError(t, l.SetLoggerDefault(unsupportedLogger{logger}))
+	status, _ = serve(t, NewHandler(), http.MethodGet, "")
+	assert.Equal(t, http.StatusNotImplemented, status, "response status")
+}
+
+type unsupportedLogger struct {
+	l.Logger
+}
@@ -0,0 +1,351 @@
+package l
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// OverflowPolicy is what the async driver does with a log call when its queue is full
+type OverflowPolicy string
+
+const (
+	// BlockOverflow waits for room in the queue, it is the default policy
+	BlockOverflow OverflowPolicy = "block"
+	// DropNewestOverflow drops the log call
+	DropNewestOverflow OverflowPolicy = "drop_newest"
+	// DropOldestOverflow drops the oldest queued entry to make room for the log call
+	DropOldestOverflow OverflowPolicy = "drop_oldest"
+	// DropBelowOverflow drops the log call when its level is below AsyncOptions.DropBelow and waits for room otherwise
+	DropBelowOverflow OverflowPolicy = "drop_below"
+)
+
+const (
+	// DefaultQueueSize is the number of entries queued by the async driver when AsyncOptions.QueueSize is zero
+	DefaultQueueSize = 1024
+	// DefaultDrainTimeout is the time Close waits for the queued entries when AsyncOptions.DrainTimeout is zero
+	DefaultDrainTimeout = 5 * time.Second
+)
+
+// AsyncOptions configures the async driver, the zero value blocks on a queue of DefaultQueueSize entries
+type AsyncOptions struct {
+	// QueueSize is the number of entries waiting to be written
+	QueueSize int
+	// Overflow is the policy for a log call when the queue is full
+	Overflow OverflowPolicy
+	// DropBelow is the level from which DropBelowOverflow waits instead of dropping, empty is WARN
+	DropBelow Level
+	// DrainTimeout is the time Close waits for the queued entries, the entries left after it are dropped
+	DrainTimeout time.Duration
+}
+
+// AsyncDriver is a Driver which queues the entries and writes them to the inner driver on a background goroutine,
+// so a slow output does not stall the log calls
+type AsyncDriver struct {
+	inner   Driver
+	queue   *asyncQueue
+	pending []Value
+}
+
+// NewAsyncDriver creates an AsyncDriver writing to the inner driver, Close drains the queue and closes the inner driver.
+// The level check and the Lazy values run on the log call, PANIC and FATAL entries are written after the queued ones
+// before the log call returns.
+func NewAsyncDriver(inner Driver, options AsyncOptions) (*AsyncDriver, error) {
+	if inner == nil {
+		return nil, fmt.Errorf("err_invalid_async{Message='inner driver is nil'}")
+	}
+	if options.QueueSize < 0 {
+		return nil, fmt.Errorf("err_invalid_async{QueueSize=%d}", options.QueueSize)
+	}
+	if options.QueueSize == 0 {
+		options.QueueSize = DefaultQueueSize
+	}
+	switch options.Overflow {
+	case "":
+		options.Overflow = BlockOverflow
+	case BlockOverflow, DropNewestOverflow, DropOldestOverflow, DropBelowOverflow:
+	default:
+		return nil, fmt.Errorf("err_invalid_async{Overflow=%q}", options.Overflow)
+	}
+	if options.DropBelow == "" {
+		options.DropBelow = WARN
+	}
+	if _, ok := options.DropBelow.severity(); !ok {
+		return nil, fmt.Errorf("err_invalid_async{DropBelow=%q}", options.DropBelow)
+	}
+	if options.DrainTimeout <= 0 {
+		options.DrainTimeout = DefaultDrainTimeout
+	}
+	queue := &asyncQueue{
+		options: options,
+		entries: make([]asyncEntry, options.QueueSize),
+		inner:   inner,
+		done:    make(chan struct{}),
+	}
+	queue.cond = sync.NewCond(&queue.mutex)
+	go queue.run()
+	return &AsyncDriver{inner: inner, queue: queue}, nil
+}
+
+// Log checks the level on the inner driver and returns a writer which queues the entry
+func (driver *AsyncDriver) Log(level Level, msg string) LogWriter {
+	writer := driver.inner.Log(level, msg)
+	if writer == nil {
+		return nil
+	}
+	async := asyncWriter{queue: driver.queue, level: level, writer: writer, pending: driver.pending}
+	switch writer.(type) {
+	case ContextWriter, CallerWriter:
+		return &asyncContextWriter{asyncWriter: async}
+	default:
+		return &async
+	}
+}
+
+// With keeps the Lazy values, and the values after them, to resolve them on the log call instead of the flusher goroutine
+func (driver *AsyncDriver) With(values ...Value) Driver {
+	if len(values) == 0 {
+		return driver
+	}
+	encode, pending := SplitWith(driver.pending, values)
+	inner := driver.inner
+	if len(encode) > 0 {
+		inner = inner.With(encode...)
+	}
+	return &AsyncDriver{inner: inner, queue: driver.queue, pending: pending}
+}
+
+func (driver *AsyncDriver) Named(name string) Driver {
+	if name == "" {
+		return driver
+	}
+	return &AsyncDriver{inner: driver.inner.Named(name), queue: driver.queue, pending: driver.pending}
+}
+
+// Sync waits for the queued entries to be written, or for the context to be done, and syncs the inner driver
+func (driver *AsyncDriver) Sync(ctx context.Context) error {
+	if err := driver.queue.drain(ctx); err != nil {
+		return err
+	}
+	return driver.inner.Sync(ctx)
+}
+
+// Close writes the queued entries until the drain timeout or the context is done, drops the remaining ones
+// and closes the inner driver, an entry still being written closes the inner driver once its write returns.
+// The children share the queue so closing any of them closes all
+func (driver *AsyncDriver) Close(ctx context.Context) error {
+	return driver.queue.close(ctx)
+}
+
+// Dropped returns the number of entries dropped by the overflow policy, the drain timeout or a log call after Close
+func (driver *AsyncDriver) Dropped() uint64 {
+	return atomic.LoadUint64(&driver.queue.dropped)
+}
+
+type asyncWriter struct {
+	queue   *asyncQueue
+	level   Level
+	writer  LogWriter
+	pending []Value
+}
+
+// resolve resolves the pending With values and the values of the log call on the caller goroutine
+func (writer *asyncWriter) resolve(values []Value) []Value {
+	if len(writer.pending) > 0 {
+		values = append(append(make([]Value, 0, len(writer.pending)+len(values)), writer.pending...), values...)
+	}
+	return resolveValues(values)
+}
+
+func (writer *asyncWriter) Write(values ...Value) {
+	values = writer.resolve(values)
+	writer.queue.push(writer.level, func() {
+		writer.writer.Write(values...)
+	})
+}
+
+// asyncContextWriter asks the Logger for the context and the call site only when the inner writer uses them,
+// the context is passed as is and its cancellation is not checked
+type asyncContextWriter struct {
+	asyncWriter
+}
+
+func (writer *asyncContextWriter) WriteContext(ctx context.Context, caller Caller, values ...Value) {
+	values = writer.resolve(values)
+	writer.queue.push(writer.level, func() {
+		writeContext(writer.writer, ctx, caller, values)
+	})
+}
+
+type asyncEntry struct {
+	level Level
+	write func()
+}
+
+// asyncQueue is a ring of entries written by the run goroutine, the cond signals every change of its state
+type asyncQueue struct {
+	options AsyncOptions
+	mutex   sync.Mutex
+	cond    *sync.Cond
+	entries []asyncEntry
+	head    int
+	count   int
+	writing bool
+	closed  bool
+	dropped uint64
+	inner   Driver
+	once    sync.Once
+	done    chan struct{}
+}
+
+func (queue *asyncQueue) push(level Level, write func()) {
+	queue.mutex.Lock()
+	defer queue.mutex.Unlock()
+
+	// the process stops after PANIC and FATAL entries, so they are written once the queue is empty
+	if level == PANIC || level == FATAL {
+		for !queue.closed && (queue.count > 0 || queue.writing) {
+			queue.cond.Wait()
+		}
+		if queue.closed {
+			queue.drop(level)
+			return
+		}
+		write()
+		return
+	}
+	for !queue.closed && queue.count == len(queue.entries) {
+		switch queue.options.Overflow {
+		case DropNewestOverflow:
+			queue.drop(level)
+			return
+		case DropOldestOverflow:
+			queue.drop(queue.entries[queue.head].level)
+			queue.entries[queue.head] = asyncEntry{}
+			queue.head = (queue.head + 1) % len(queue.entries)
+			queue.count--
+		case DropBelowOverflow:
+			if !level.Enabled(queue.options.DropBelow) {
+				queue.drop(level)
+				return
+			}
+			queue.cond.Wait()
+		default:
+			queue.cond.Wait()
+		}
+	}
+	if queue.closed {
+		queue.drop(level)
+		return
+	}
+	queue.entries[(queue.head+queue.count)%len(queue.entries)] = asyncEntry{level: level, write: write}
+	queue.count++
+	queue.cond.Broadcast()
+}
+
+// drop counts a dropped entry on the driver and on Stats
+func (queue *asyncQueue) drop(level Level) {
+	atomic.AddUint64(&queue.dropped, 1)
+	countDropped(level)
+}
+
+// run writes the entries until the queue is closed and empty
+func (queue *asyncQueue) run() {
+	defer close(queue.done)
+	queue.mutex.Lock()
+	defer queue.mutex.Unlock()
+	for {
+		for queue.count == 0 && !queue.closed {
+			queue.cond.Wait()
+		}
+		if queue.count == 0 {
+			return
+		}
+		entry := queue.entries[queue.head]
+		queue.entries[queue.head] = asyncEntry{}
+		queue.head = (queue.head + 1) % len(queue.entries)
+		queue.count--
+		queue.writing = true
+		queue.cond.Broadcast()
+
+		queue.mutex.Unlock()
+		entry.write()
+		queue.mutex.Lock()
+
+		queue.writing = false
+		queue.cond.Broadcast()
+	}
+}
+
+// drain waits until the queue is empty and no entry is being written, or until the context is done
+func (queue *asyncQueue) drain(ctx context.Context) error {
+	drained := make(chan struct{})
+	go func() {
+		queue.mutex.Lock()
+		for queue.count > 0 || queue.writing {
+			queue.cond.Wait()
+		}
+		queue.mutex.Unlock()
+		close(drained)
+	}()
+	select {
+	case <-drained:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (queue *asyncQueue) close(ctx context.Context) error {
+	var errs []error
+	queue.once.Do(func() {
+		queue.mutex.Lock()
+		queue.closed = true
+		queue.cond.Broadcast()
+		queue.mutex.Unlock()
+
+		timer := time.NewTimer(queue.options.DrainTimeout)
+		defer timer.Stop()
+		select {
+		case <-queue.done:
+		case <-timer.C:
+			errs = append(errs, queue.abandon())
+		case <-ctx.Done():
+			errs = append(errs, queue.abandon())
+		}
+		select {
+		case <-queue.done:
+			if err := queue.inner.Close(ctx); err != nil {
+				errs = append(errs, err)
+			}
+		default:
+			// the entry being written keeps the inner driver open, it is closed once the write returns
+			go func() {
+				<-queue.done
+				if err := queue.inner.Close(context.Background()); err != nil {
+					HandleError(err)
+				}
+			}()
+		}
+	})
+	return errors.Join(errs...)
+}
+
+// abandon drops the queued entries, the entry being written is left to the flusher goroutine
+func (queue *asyncQueue) abandon() error {
+	queue.mutex.Lock()
+	dropped := queue.count
+	for index := 0; index < queue.count; index++ {
+		queue.drop(queue.entries[(queue.head+index)%len(queue.entries)].level)
+	}
+	for index := range queue.entries {
+		queue.entries[index] = asyncEntry{}
+	}
+	queue.head, queue.count = 0, 0
+	queue.cond.Broadcast()
+	queue.mutex.Unlock()
+	return fmt.Errorf("err_async_drain{Dropped=%d}", dropped)
+}
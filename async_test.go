@@ -0,0 +1,451 @@
+package l
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testAsyncOutput records the messages, every write waits the gate to be closed
+type testAsyncOutput struct {
+	mutex    sync.Mutex
+	messages []string
+	gate     chan struct{}
+	started  chan struct{}
+	closed   bool
+}
+
+func newTestAsyncOutput() *testAsyncOutput {
+	return &testAsyncOutput{gate: make(chan struct{}), started: make(chan struct{}, 1000)}
+}
+
+func (output *testAsyncOutput) Closed() bool {
+	output.mutex.Lock()
+	defer output.mutex.Unlock()
+	return output.closed
+}
+
+func (output *testAsyncOutput) Messages() []string {
+	output.mutex.Lock()
+	defer output.mutex.Unlock()
+	return append([]string(nil), output.messages...)
+}
+
+type testAsyncDriver struct {
+	output *testAsyncOutput
+}
+
+func (driver testAsyncDriver) Log(level Level, msg string) LogWriter {
+	return &testAsyncWriter{output: driver.output, msg: msg}
+}
+
+func (driver testAsyncDriver) With(...Value) Driver {
+	return driver
+}
+
+func (driver testAsyncDriver) Named(string) Driver {
+	return driver
+}
+
+func (driver testAsyncDriver) Sync(context.Context) error {
+	return nil
+}
+
+func (driver testAsyncDriver) Close(context.Context) error {
+	driver.output.mutex.Lock()
+	driver.output.closed = true
+	driver.output.mutex.Unlock()
+	return nil
+}
+
+type testAsyncWriter struct {
+	output *testAsyncOutput
+	msg    string
+}
+
+func (writer *testAsyncWriter) Write(...Value) {
+	writer.output.started <- struct{}{}
+	<-writer.output.gate
+	writer.output.mutex.Lock()
+	writer.output.messages = append(writer.output.messages, writer.msg)
+	writer.output.mutex.Unlock()
+}
+
+type testAsyncOverflow struct {
+	name     string
+	options  AsyncOptions
+	levels   []Level
+	expected []string
+	dropped  uint64
+}
+
+func TestAsyncDriverOverflow(test *testing.T) {
+	scenarios := []testAsyncOverflow{
+		{
+			name:     "Drops the newest entries",
+			options:  AsyncOptions{QueueSize: 2, Overflow: DropNewestOverflow},
+			levels:   []Level{INFO, INFO, INFO, INFO, INFO},
+			expected: []string{"log0", "log1", "log2"},
+			dropped:  2,
+		},
+		{
+			name:     "Drops the oldest entries",
+			options:  AsyncOptions{QueueSize: 2, Overflow: DropOldestOverflow},
+			levels:   []Level{INFO, INFO, INFO, INFO, INFO},
+			expected: []string{"log0", "log3", "log4"},
+			dropped:  2,
+		},
+		{
+			name:     "Drops the entries below the level",
+			options:  AsyncOptions{QueueSize: 2, Overflow: DropBelowOverflow, DropBelow: INFO},
+			levels:   []Level{INFO, INFO, WARN, DEBUG, TRACE},
+			expected: []string{"log0", "log1", "log2"},
+			dropped:  2,
+		},
+		{
+			name:     "Does not drop entries with room in the queue",
+			options:  AsyncOptions{QueueSize: 4, Overflow: DropNewestOverflow},
+			levels:   []Level{INFO, INFO, INFO, INFO, INFO},
+			expected: []string{"log0", "log1", "log2", "log3", "log4"},
+		},
+	}
+
+	for index, scenario := range scenarios {
+		test.Run(
+			fmt.Sprintf("[%d]-%s", index, scenario.name),
+			func(t *testing.T) {
+				output := newTestAsyncOutput()
+				driver, err := NewAsyncDriver(testAsyncDriver{output: output}, scenario.options)
+				assert.NoError(t, err, "async driver")
+				before := Stats()
+
+				// the first entry is written and waits the gate while the others fill the queue
+				for levelIndex, level := range scenario.levels {
+					driver.Log(level, fmt.Sprintf("log%d", levelIndex)).Write()
+					if levelIndex == 0 {
+						<-output.started
+					}
+				}
+				close(output.gate)
+				assert.NoError(t, driver.Close(context.Background()), "close driver")
+
+				assert.Equal(t, scenario.expected, output.Messages(), "messages")
+				assert.Equal(t, scenario.dropped, driver.Dropped(), "dropped")
+				assert.Equal(t, scenario.dropped, statsDelta(before, Stats()).Total().Dropped, "dropped stats")
+				assert.True(t, output.closed, "inner closed")
+			},
+		)
+	}
+}
+
+type testAsyncBlock struct {
+	name    string
+	options AsyncOptions
+	level   Level
+}
+
+func TestAsyncDriverBlock(test *testing.T) {
+	scenarios := []testAsyncBlock{
+		{
+			name:    "Blocks by default",
+			options: AsyncOptions{QueueSize: 1},
+			level:   DEBUG,
+		},
+		{
+			name:    "Blocks the entries from the drop level",
+			options: AsyncOptions{QueueSize: 1, Overflow: DropBelowOverflow},
+			level:   WARN,
+		},
+	}
+
+	for index, scenario := range scenarios {
+		test.Run(
+			fmt.Sprintf("[%d]-%s", index, scenario.name),
+			func(t *testing.T) {
+				output := newTestAsyncOutput()
+				driver, err := NewAsyncDriver(testAsyncDriver{output: output}, scenario.options)
+				assert.NoError(t, err, "async driver")
+
+				driver.Log(scenario.level, "log0").Write()
+				<-output.started
+				driver.Log(scenario.level, "log1").Write()
+
+				written := make(chan struct{})
+				go func() {
+					driver.Log(scenario.level, "log2").Write()
+					close(written)
+				}()
+				select {
+				case <-written:
+					t.Fatal("log call did not block on a full queue")
+				case <-time.After(50 * time.Millisecond):
+				}
+				close(output.gate)
+				<-written
+				assert.NoError(t, driver.Close(context.Background()), "close driver")
+
+				assert.Equal(t, []string{"log0", "log1", "log2"}, output.Messages(), "messages")
+				assert.Equal(t, uint64(0), driver.Dropped(), "dropped")
+			},
+		)
+	}
+}
+
+func TestAsyncDriverClose(t *testing.T) {
+	output := newTestAsyncOutput()
+	driver, err := NewAsyncDriver(testAsyncDriver{output: output}, AsyncOptions{DrainTimeout: 20 * time.Millisecond})
+	assert.NoError(t, err, "async driver")
+
+	driver.Log(INFO, "log0").Write()
+	<-output.started
+	driver.Log(INFO, "log1").Write()
+	driver.Log(INFO, "log2").Write()
+
+	closed := make(chan error)
+	go func() {
+		closed <- driver.Close(context.Background())
+	}()
+	// the drain timeout drops the queued entries and Close returns while the entry is being written
+	select {
+	case err := <-closed:
+		assert.EqualError(t, err, "err_async_drain{Dropped=2}", "close error")
+	case <-time.After(time.Second):
+		t.Fatal("close waited the entry being written")
+	}
+	assert.Equal(t, uint64(2), driver.Dropped(), "dropped")
+	assert.False(t, output.Closed(), "inner closed under the entry being written")
+
+	close(output.gate)
+	assert.Eventually(t, output.Closed, time.Second, time.Millisecond, "inner closed after the write")
+	assert.Equal(t, []string{"log0"}, output.Messages(), "messages")
+
+	driver.Log(INFO, "log3").Write()
+	assert.NoError(t, driver.Named("child").Close(context.Background()), "close closed driver")
+	assert.Equal(t, []string{"log0"}, output.Messages(), "messages after close")
+	assert.Equal(t, uint64(3), driver.Dropped(), "dropped after close")
+}
+
+func TestAsyncDriverCloseContext(t *testing.T) {
+	output := newTestAsyncOutput()
+	driver, err := NewAsyncDriver(testAsyncDriver{output: output}, AsyncOptions{DrainTimeout: time.Minute})
+	assert.NoError(t, err, "async driver")
+	defer close(output.gate)
+
+	driver.Log(INFO, "log0").Write()
+	<-output.started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	assert.EqualError(t, driver.Close(ctx), "err_async_drain{Dropped=0}", "close error")
+	assert.True(t, time.Since(start) < time.Second, "close after the context deadline")
+	assert.False(t, output.Closed(), "inner closed under the entry being written")
+}
+
+func TestAsyncDriverSync(t *testing.T) {
+	output := newTestAsyncOutput()
+	driver, err := NewAsyncDriver(testAsyncDriver{output: output}, AsyncOptions{})
+	assert.NoError(t, err, "async driver")
+
+	driver.Log(INFO, "log0").Write()
+	<-output.started
+	driver.Log(INFO, "log1").Write()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	assert.Equal(t, context.DeadlineExceeded, driver.Sync(ctx), "sync timeout")
+
+	close(output.gate)
+	assert.NoError(t, driver.Sync(context.Background()), "sync driver")
+	assert.Equal(t, []string{"log0", "log1"}, output.Messages(), "synced messages")
+
+	// a done context stops the drain of Close like the drain timeout
+	<-output.started
+	output.gate = make(chan struct{})
+	driver.Log(INFO, "log2").Write()
+	<-output.started
+	driver.Log(INFO, "log3").Write()
+	cancelled, cancelClose := context.WithCancel(context.Background())
+	cancelClose()
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		close(output.gate)
+	}()
+	assert.EqualError(t, driver.Close(cancelled), "err_async_drain{Dropped=1}", "close error")
+	assert.Eventually(t, output.Closed, time.Second, time.Millisecond, "inner closed after the write")
+	assert.Equal(t, []string{"log0", "log1", "log2"}, output.Messages(), "closed messages")
+}
+
+func TestAsyncDriverDrain(t *testing.T) {
+	var (
+		buffer      = new(bytes.Buffer)
+		driver, err = NewAsyncDriver(NewSlogDriver(slog.NewJSONHandler(buffer, nil)), AsyncOptions{QueueSize: 16})
+		goroutines  = 8
+		entries     = 200
+		writers     sync.WaitGroup
+	)
+	assert.NoError(t, err, "async driver")
+	log := New(driver).Named("api").With(String("key", "value"))
+	for goroutine := 0; goroutine < goroutines; goroutine++ {
+		writers.Add(1)
+		go func(goroutine int) {
+			defer writers.Done()
+			for index := 0; index < entries; index++ {
+				log.Info(context.Background(), "infolog", Int("goroutine", goroutine), Int("index", index))
+			}
+		}(goroutine)
+	}
+	writers.Wait()
+	assert.NoError(t, driver.Close(context.Background()), "close driver")
+
+	lines := strings.Split(strings.TrimSpace(buffer.String()), "\n")
+	assert.Len(t, lines, goroutines*entries, "entries")
+	assert.Equal(t, uint64(0), driver.Dropped(), "dropped")
+	next := make(map[float64]float64)
+	for _, line := range lines {
+		var fields map[string]interface{}
+		assert.NoError(t, json.Unmarshal([]byte(line), &fields), "json entry")
+		assert.Equal(t, "api", fields[SlogNameKey], "logger name")
+		assert.Equal(t, "value", fields["key"], "with value")
+		goroutine := fields["goroutine"].(float64)
+		assert.Equal(t, next[goroutine], fields["index"], "goroutine %v order", goroutine)
+		next[goroutine]++
+	}
+}
+
+func TestAsyncDriverWriters(t *testing.T) {
+	var (
+		writer      = new(testContextWriter)
+		driver, err = NewAsyncDriver(testContextDriver{writer: writer}, AsyncOptions{})
+		log         = New(driver)
+		ctx         = WithValues(context.Background(), String("requestid", "request1"))
+		calls       int
+	)
+	assert.NoError(t, err, "async driver")
+	line := currentLine() + 1
+	log.Info(ctx, "infolog", Lazy("lazy", func() interface{} { calls++; return calls }))
+	assert.Equal(t, 1, calls, "lazy resolved on the log call")
+	assert.NoError(t, driver.Close(context.Background()), "close driver")
+
+	assert.Equal(t, ctx, writer.ctx, "writer context")
+	assert.Equal(t, line, writer.caller.Frame().Line, "caller line")
+	assert.Equal(t, []Value{String("requestid", "request1"), NewValue("lazy", 1)}, writer.values, "writer values")
+}
+
+func TestAsyncDriverLazy(t *testing.T) {
+	var (
+		writer      = new(testCallerWriter)
+		driver, err = NewAsyncDriver(testCallerDriver{writer: writer}, AsyncOptions{})
+		calls       int
+		lazy        = func() interface{} { calls++; return calls }
+	)
+	assert.NoError(t, err, "async driver")
+	log := New(driver).With(String("service", "api"), Lazy("with", lazy), Group("with_group", Lazy("nested", lazy)))
+	log.Info(context.Background(), "infolog", Group("group", Int("key", 1), Lazy("nested", lazy)))
+	assert.Equal(t, 3, calls, "lazy values resolved on the log call")
+	assert.NoError(t, driver.Close(context.Background()), "close driver")
+
+	assert.Equal(
+		t,
+		[]Value{
+			NewValue("with", 1),
+			Group("with_group", NewValue("nested", 2)),
+			Group("group", Int("key", 1), NewValue("nested", 3)),
+		},
+		writer.values,
+		"writer values",
+	)
+}
+
+func TestAsyncDriverFatal(t *testing.T) {
+	var exitCodes []int
+	exit = func(code int) { exitCodes = append(exitCodes, code) }
+	defer func() { exit = os.Exit }()
+
+	output := newTestAsyncOutput()
+	close(output.gate)
+	driver, err := NewAsyncDriver(testAsyncDriver{output: output}, AsyncOptions{})
+	assert.NoError(t, err, "async driver")
+	log := New(driver)
+
+	log.Info(context.Background(), "infolog")
+	log.Warn(context.Background(), "warnlog")
+	log.Fatal(context.Background(), "fatallog")
+	assert.Equal(t, []string{"infolog", "warnlog", "fatallog"}, output.Messages(), "messages before close")
+	assert.Equal(t, []int{1}, exitCodes, "exit codes")
+	assert.NoError(t, driver.Close(context.Background()), "close driver")
+}
+
+type testAsyncOptions struct {
+	name    string
+	inner   Driver
+	options AsyncOptions
+	err     string
+}
+
+func TestNewAsyncDriverError(test *testing.T) {
+	inner := testAsyncDriver{output: newTestAsyncOutput()}
+	scenarios := []testAsyncOptions{
+		{
+			name: "Rejects a nil driver",
+			err:  "err_invalid_async{Message='inner driver is nil'}",
+		},
+		{
+			name:    "Rejects a negative queue size",
+			inner:   inner,
+			options: AsyncOptions{QueueSize: -1},
+			err:     "err_invalid_async{QueueSize=-1}",
+		},
+		{
+			name:    "Rejects an unknown overflow policy",
+			inner:   inner,
+			options: AsyncOptions{Overflow: "drop_all"},
+			err:     `err_invalid_async{Overflow="drop_all"}`,
+		},
+		{
+			name:    "Rejects an unknown drop level",
+			inner:   inner,
+			options: AsyncOptions{Overflow: DropBelowOverflow, DropBelow: "verbose"},
+			err:     `err_invalid_async{DropBelow="verbose"}`,
+		},
+	}
+
+	for index, scenario := range scenarios {
+		test.Run(
+			fmt.Sprintf("[%d]-%s", index, scenario.name),
+			func(t *testing.T) {
+				driver, err := NewAsyncDriver(scenario.inner, scenario.options)
+				assert.Nil(t, driver, "async driver")
+				assert.EqualError(t, err, scenario.err, "async driver error")
+			},
+		)
+	}
+}
+
+func BenchmarkAsyncDriver(b *testing.B) {
+	driver, err := NewAsyncDriver(
+		NewSlogDriver(slog.NewJSONHandler(ioutil.Discard, nil)),
+		AsyncOptions{Overflow: DropNewestOverflow},
+	)
+	if err != nil {
+		b.Fatal(err)
+	}
+	log := New(driver)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for index := 0; index < b.N; index++ {
+		log.Info(context.Background(), "infolog", String("key", "value"))
+	}
+	b.StopTimer()
+	_ = driver.Close(context.Background())
+}
@@ -0,0 +1,56 @@
+package l
+
+import (
+	"context"
+	"runtime"
+)
+
+// Caller is the call site of a log call, its symbols are resolved only when a driver asks for them
+type Caller struct {
+	pc uintptr
+}
+
+// CallerAt captures the call site skip frames above the function calling CallerAt, 0 is that function itself
+func CallerAt(skip int) Caller {
+	var pcs [1]uintptr
+	if runtime.Callers(skip+2, pcs[:]) < 1 {
+		return Caller{}
+	}
+	return Caller{pc: pcs[0]}
+}
+
+// NewCaller creates a Caller from a program counter returned by runtime.Callers, like the PC of a slog.Record
+func NewCaller(pc uintptr) Caller {
+	return Caller{pc: pc}
+}
+
+// Defined reports whether the call site was captured
+func (caller Caller) Defined() bool {
+	return caller.pc != 0
+}
+
+// PC returns the program counter of the call site as returned by runtime.Callers
+func (caller Caller) PC() uintptr {
+	return caller.pc
+}
+
+// Frame resolves the file, line and function of the call site
+func (caller Caller) Frame() runtime.Frame {
+	if caller.pc == 0 {
+		return runtime.Frame{}
+	}
+	frame, _ := runtime.CallersFrames([]uintptr{caller.pc}).Next()
+	return frame
+}
+
+// CallerWriter is implemented by a LogWriter that reports the call site, the Logger captures the Caller only for those writers
+type CallerWriter interface {
+	LogWriter
+	WriteCaller(Caller, ...Value)
+}
+
+// CallerLogger is implemented by a Logger that accepts the call site from a bridge, like a slog.Handler, instead of capturing it
+type CallerLogger interface {
+	Logger
+	LogCaller(ctx context.Context, level Level, caller Caller, msg string, values ...Value)
+}
@@ -0,0 +1,177 @@
+package l
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func currentLine() int {
+	_, _, line, _ := runtime.Caller(1)
+	return line
+}
+
+type testCallerWriter struct {
+	caller Caller
+	values []Value
+}
+
+func (writer *testCallerWriter) Write(values ...Value) {
+	writer.values = values
+}
+
+func (writer *testCallerWriter) WriteCaller(caller Caller, values ...Value) {
+	writer.caller = caller
+	writer.values = values
+}
+
+type testCallerDriver struct {
+	writer *testCallerWriter
+}
+
+func (driver testCallerDriver) Log(Level, string) LogWriter {
+	return driver.writer
+}
+
+func (driver testCallerDriver) With(...Value) Driver {
+	return driver
+}
+
+func (driver testCallerDriver) Named(string) Driver {
+	return driver
+}
+
+func (driver testCallerDriver) Sync(context.Context) error {
+	return nil
+}
+
+func (driver testCallerDriver) Close(context.Context) error {
+	return nil
+}
+
+type testCaller struct {
+	name string
+	log  func(Logger) int
+}
+
+func TestCaller(test *testing.T) {
+	scenarios := []testCaller{
+		{
+			name: "Reports the Logger method call site",
+			log: func(log Logger) int {
+				line := currentLine() + 1
+				log.Info(context.Background(), "infolog")
+				return line
+			},
+		},
+		{
+			name: "Reports the child Logger method call site",
+			log: func(log Logger) int {
+				line := currentLine() + 1
+				log.Named("api").With(String("key", "value")).Warn(context.Background(), "warnlog")
+				return line
+			},
+		},
+		{
+			name: "Reports the package level function call site",
+			log: func(log Logger) int {
+				SetLoggerDefault(log)
+				line := currentLine() + 1
+				Error(context.Background(), "errorlog")
+				return line
+			},
+		},
+		{
+			name: "Reports the package level trace function call site",
+			log: func(log Logger) int {
+				SetLoggerDefault(log)
+				line := currentLine() + 1
+				Trace(context.Background(), "tracelog")
+				return line
+			},
+		},
+	}
+
+	defaultLogger := LoggerDefault
+	defer SetLoggerDefault(defaultLogger)
+
+	for index, scenario := range scenarios {
+		test.Run(
+			fmt.Sprintf("[%d]-%s", index, scenario.name),
+			func(t *testing.T) {
+				writer := new(testCallerWriter)
+				line := scenario.log(New(testCallerDriver{writer: writer}))
+
+				assert.True(t, writer.caller.Defined(), "caller defined")
+				frame := writer.caller.Frame()
+				assert.Equal(t, "caller_test.go", filepath.Base(frame.File), "caller file")
+				assert.Equal(t, line, frame.Line, "caller line")
+				assert.Contains(t, frame.Function, "TestCaller", "caller function")
+			},
+		)
+	}
+}
+
+func TestCallerUndefined(t *testing.T) {
+	var caller Caller
+	assert.False(t, caller.Defined(), "caller defined")
+	assert.Equal(t, uintptr(0), caller.PC(), "caller pc")
+	assert.Equal(t, runtime.Frame{}, caller.Frame(), "caller frame")
+	assert.False(t, CallerAt(1000).Defined(), "deep caller defined")
+}
+
+type testContextWriter struct {
+	testCallerWriter
+	ctx context.Context
+}
+
+func (writer *testContextWriter) WriteContext(ctx context.Context, caller Caller, values ...Value) {
+	writer.ctx = ctx
+	writer.WriteCaller(caller, values...)
+}
+
+type testContextDriver struct {
+	testCallerDriver
+	writer *testContextWriter
+}
+
+func (driver testContextDriver) Log(Level, string) LogWriter {
+	return driver.writer
+}
+
+func TestContextWriter(t *testing.T) {
+	var (
+		writer = new(testContextWriter)
+		log    = New(testContextDriver{writer: writer})
+		ctx    = WithValues(context.Background(), String("requestid", "request1"))
+	)
+	line := currentLine() + 1
+	log.Info(ctx, "infolog", Int("key", 1))
+
+	assert.Equal(t, ctx, writer.ctx, "writer context")
+	assert.Equal(t, []Value{String("requestid", "request1"), Int("key", 1)}, writer.values, "writer values")
+	assert.Equal(t, line, writer.caller.Frame().Line, "caller line")
+}
+
+func TestLogCaller(t *testing.T) {
+	var (
+		writer      = new(testContextWriter)
+		log         = New(testContextDriver{writer: writer})
+		pc, _, _, _ = runtime.Caller(0)
+		caller      = NewCaller(pc)
+	)
+	callerLogger, ok := log.(CallerLogger)
+	assert.True(t, ok, "caller logger")
+
+	callerLogger.LogCaller(context.Background(), INFO, caller, "infolog")
+	assert.Equal(t, caller, writer.caller, "provided caller")
+
+	log.SetLevel(ERROR)
+	writer.caller = Caller{}
+	callerLogger.LogCaller(context.Background(), INFO, caller, "infolog")
+	assert.False(t, writer.caller.Defined(), "disabled level caller")
+}
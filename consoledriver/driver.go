@@ -0,0 +1,270 @@
+// Package consoledriver is an l.Driver which writes aligned and colored entries for humans reading a terminal,
+// like a developer running the service locally
+package consoledriver
+
+import (
+	"context"
+	"io"
+	"os"
+	"time"
+	"unicode/utf8"
+
+	"github.com/rjansen/l"
+	"github.com/rjansen/l/internal/driverutil"
+)
+
+const (
+	// packagePath prefixes the frames of the driver, skipped by the stacktraces without caller
+	packagePath = "github.com/rjansen/l/consoledriver"
+
+	// TimeLayout is the short layout of the entry time written by the driver
+	TimeLayout = "15:04:05.000"
+	// messageWidth is the column where the fields start when the message is shorter
+	messageWidth = 40
+	// blockIndent prefixes every line of the multi-line errors and stacktraces written after the entry
+	blockIndent = "    "
+)
+
+// Option customizes the console driver
+type Option func(*options)
+
+type options struct {
+	driverutil.Options
+	timeLayout string
+	color      *bool
+}
+
+func newOptions(opts []Option) options {
+	options := options{
+		Options:    driverutil.NewOptions(),
+		timeLayout: TimeLayout,
+	}
+	for _, option := range opts {
+		option(&options)
+	}
+	return options
+}
+
+// shared adapts a driverutil.Option to the options of the driver
+func shared(option driverutil.Option) Option {
+	return func(options *options) {
+		option(&options.Options)
+	}
+}
+
+// WithLevel sets the lowest level written by the driver, TRACE by default so the Logger threshold decides
+func WithLevel(level l.Level) Option {
+	return shared(driverutil.WithLevel(level))
+}
+
+// WithCaller enables the caller file and line on the entries, it is disabled by default
+func WithCaller(enabled bool) Option {
+	return shared(driverutil.WithCaller(enabled))
+}
+
+// WithStacktraceLevel sets the level from which entries are followed by a stacktrace, ERROR by default
+func WithStacktraceLevel(level l.Level) Option {
+	return shared(driverutil.WithStacktraceLevel(level))
+}
+
+// WithClock replaces the time source of the entries
+func WithClock(now func() time.Time) Option {
+	return shared(driverutil.WithClock(now))
+}
+
+// WithTimeLayout replaces the TimeLayout of the entry time, an empty layout omits the time
+func WithTimeLayout(layout string) Option {
+	return func(options *options) {
+		options.timeLayout = layout
+	}
+}
+
+// WithColor forces the ANSI colors on or off, by default they are enabled only when every output is a terminal
+func WithColor(enabled bool) Option {
+	return func(options *options) {
+		options.color = &enabled
+	}
+}
+
+// isTerminal reports whether every writer is a file attached to a terminal
+func isTerminal(writers ...io.Writer) bool {
+	for _, writer := range writers {
+		file, ok := writer.(*os.File)
+		if !ok {
+			return false
+		}
+		info, err := file.Stat()
+		if err != nil || info.Mode()&os.ModeCharDevice == 0 {
+			return false
+		}
+	}
+	return len(writers) > 0
+}
+
+type driver struct {
+	sink    *driverutil.Sink
+	options options
+	color   bool
+	name    string
+	fields  []byte
+	blocks  []byte
+	prefix  string
+	// pending are the values of With from the first lazy value on, they are resolved on every write
+	pending []l.Value
+}
+
+func newDriver(sink *driverutil.Sink, opts []Option) *driver {
+	options := newOptions(opts)
+	color := isTerminal(sink.Writers()...)
+	if options.color != nil {
+		color = *options.color
+	}
+	return &driver{
+		sink:    sink,
+		options: options,
+		color:   color,
+	}
+}
+
+// New creates a Driver which writes console entries to the writer, the writer is not closed by the driver
+func New(writer io.Writer, opts ...Option) l.Driver {
+	return newDriver(driverutil.NewSink(writer), opts)
+}
+
+// Open creates a Driver which writes console entries to every destination of the output within its level range,
+// the files are rotated and reopened like the ones of zapdriver.NewLogger and are closed by Close
+func Open(out l.Out, opts ...Option) (l.Driver, error) {
+	sink, err := driverutil.Open(out)
+	if err != nil {
+		return nil, err
+	}
+	return newDriver(sink, opts), nil
+}
+
+func (driver *driver) Log(level l.Level, msg string) l.LogWriter {
+	if !level.Enabled(driver.options.Level) || !driver.sink.Enabled(level) {
+		return nil
+	}
+	entry := &writer{
+		driver: driver,
+		level:  level,
+		msg:    msg,
+		time:   driver.options.Now(),
+	}
+	// the Logger captures the call site only for a CallerWriter, so it is returned only when the entry uses it
+	if driver.options.Caller || level.Enabled(driver.options.StacktraceLevel) {
+		return &callerWriter{writer: entry}
+	}
+	return entry
+}
+
+// With formats the values once for the child, the lazy values are kept unresolved and formatted on every write
+func (driver *driver) With(values ...l.Value) l.Driver {
+	if len(values) == 0 {
+		return driver
+	}
+	child := *driver
+	values, child.pending = l.SplitWith(driver.pending, values)
+	if len(values) > 0 {
+		format := formatter{
+			buffer: append([]byte(nil), driver.fields...),
+			blocks: append([]byte(nil), driver.blocks...),
+			prefix: driver.prefix,
+			color:  driver.color,
+		}
+		format.appendValues(values)
+		child.fields = format.buffer
+		child.blocks = format.blocks
+		child.prefix = format.prefix
+	}
+	return &child
+}
+
+func (driver *driver) Named(name string) l.Driver {
+	if name == "" {
+		return driver
+	}
+	child := *driver
+	child.name = l.JoinName(driver.name, name)
+	return &child
+}
+
+func (driver *driver) Sync(context.Context) error {
+	return driver.sink.Sync()
+}
+
+// Close syncs the writer and closes a file opened by Open, the children share it
+func (driver *driver) Close(context.Context) error {
+	return driver.sink.Close()
+}
+
+type writer struct {
+	driver *driver
+	level  l.Level
+	msg    string
+	time   time.Time
+}
+
+func (writer *writer) Write(values ...l.Value) {
+	writer.write(l.Caller{}, values)
+}
+
+type callerWriter struct {
+	*writer
+}
+
+func (writer *callerWriter) WriteCaller(caller l.Caller, values ...l.Value) {
+	writer.write(caller, values)
+}
+
+func (writer *writer) write(caller l.Caller, values []l.Value) {
+	var (
+		driver = writer.driver
+		buffer = driverutil.GetBuffer()
+		format = formatter{
+			buffer: *buffer,
+			prefix: driver.prefix,
+			color:  driver.color,
+		}
+	)
+	if driver.options.timeLayout != "" {
+		format.appendColored(faintColor, writer.time.Format(driver.options.timeLayout))
+		format.buffer = append(format.buffer, ' ')
+	}
+	format.appendColored(levelColor(writer.level), levelText(writer.level))
+	if driver.name != "" {
+		format.buffer = append(format.buffer, ' ')
+		format.appendColored(boldColor, driver.name)
+	}
+	if driver.options.Caller && caller.Defined() {
+		format.buffer = append(format.buffer, ' ')
+		format.appendColored(faintColor, driverutil.ShortCaller(caller.Frame()))
+	}
+	format.buffer = append(format.buffer, ' ')
+	format.buffer = append(format.buffer, writer.msg...)
+
+	messageEnd := len(format.buffer)
+	if len(driver.fields) > 0 || len(driver.pending) > 0 || len(values) > 0 {
+		// the message is padded so the fields of consecutive entries start at the same column
+		for padding := messageWidth - utf8.RuneCountInString(writer.msg); padding > 0; padding-- {
+			format.buffer = append(format.buffer, ' ')
+		}
+		fieldsStart := len(format.buffer)
+		format.buffer = append(format.buffer, driver.fields...)
+		format.appendValues(driver.pending)
+		format.appendValues(values)
+		if len(format.buffer) == fieldsStart {
+			format.buffer = format.buffer[:messageEnd]
+		}
+	}
+	format.buffer = append(format.buffer, '\n')
+	format.buffer = append(format.buffer, driver.blocks...)
+	if writer.level.Enabled(driver.options.StacktraceLevel) {
+		format.appendBlock(faintColor, "", driverutil.Stacktrace(caller, packagePath))
+	}
+	format.buffer = append(format.buffer, format.blocks...)
+
+	driver.sink.Write(writer.level, format.buffer)
+	*buffer = format.buffer
+	driverutil.PutBuffer(buffer)
+}
@@ -0,0 +1,207 @@
+package consoledriver
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rjansen/l"
+	"github.com/stretchr/testify/assert"
+)
+
+var testTime = time.Date(2019, 10, 1, 12, 30, 15, 123000000, time.UTC)
+
+func testClock() time.Time {
+	return testTime
+}
+
+type testDriver struct {
+	name     string
+	options  []Option
+	log      func(l.Logger)
+	expected []string
+}
+
+func TestDriver(test *testing.T) {
+	scenarios := []testDriver{
+		{
+			name: "Writes an entry",
+			log: func(log l.Logger) {
+				log.Info(context.Background(), "infolog", l.String("key", "value"))
+			},
+			expected: []string{
+				`12:30:15.123 INFO  infolog                                  key=value`,
+			},
+		},
+		{
+			name: "Writes an entry without fields",
+			log: func(log l.Logger) {
+				log.Debug(context.Background(), "debuglog", l.Group("empty"))
+			},
+			expected: []string{
+				`12:30:15.123 DEBUG debuglog`,
+			},
+		},
+		{
+			name: "Aligns the fields after the message",
+			log: func(log l.Logger) {
+				log.Trace(context.Background(), "tracelog", l.Int("id", 1))
+				log.Warn(context.Background(), "a warning message longer than the message column", l.Int("id", 2))
+			},
+			expected: []string{
+				`12:30:15.123 TRACE tracelog                                 id=1`,
+				`12:30:15.123 WARN  a warning message longer than the message column id=2`,
+			},
+		},
+		{
+			name: "Writes named child entries with dotted groups and namespaces",
+			log: func(log l.Logger) {
+				api := log.Named("api").With(l.String("component", "api"), l.Namespace("http"))
+				api.Named("db").With(l.Int("pool", 1)).
+					Info(context.Background(), "infolog", l.Group("response", l.Int("status", 200)), l.Group("", l.Bool("ok", true)))
+			},
+			expected: []string{
+				`12:30:15.123 INFO  api.db infolog                                  component=api http.pool=1 http.response.status=200 http.ok=true`,
+			},
+		},
+		{
+			name: "Resolves the lazy values of With on every write",
+			log: func(log l.Logger) {
+				var calls int
+				child := log.With(l.String("id", "request1"), l.Lazy("calls", func() interface{} { calls++; return calls }), l.String("method", "GET"))
+				child.Info(context.Background(), "infolog")
+				child.Info(context.Background(), "infolog", l.Int("status", 200))
+			},
+			expected: []string{
+				`12:30:15.123 INFO  infolog                                  id=request1 calls=1 method=GET`,
+				`12:30:15.123 INFO  infolog                                  id=request1 calls=2 method=GET status=200`,
+			},
+		},
+		{
+			name:    "Does not write entries below the driver level",
+			options: []Option{WithLevel(l.INFO), WithTimeLayout("")},
+			log: func(log l.Logger) {
+				log.Debug(context.Background(), "debuglog")
+				log.Info(context.Background(), "infolog")
+			},
+			expected: []string{
+				`INFO  infolog`,
+			},
+		},
+		{
+			name:    "Writes multi-line errors after the entry",
+			options: []Option{WithStacktraceLevel(l.FATAL)},
+			log: func(log l.Logger) {
+				log.With(l.NamedErr("cause", errors.New("connection refused"))).
+					Error(context.Background(), "errorlog", l.Err(errors.New("query failed\nselect 1\nfrom dual")))
+			},
+			expected: []string{
+				`12:30:15.123 ERROR errorlog                                 cause="connection refused" error="query failed"`,
+				`    error: query failed`,
+				`    select 1`,
+				`    from dual`,
+			},
+		},
+	}
+
+	for index, scenario := range scenarios {
+		test.Run(
+			fmt.Sprintf("[%d]-%s", index, scenario.name),
+			func(t *testing.T) {
+				var (
+					buffer = new(bytes.Buffer)
+					driver = New(buffer, append([]Option{WithClock(testClock)}, scenario.options...)...)
+				)
+				scenario.log(l.New(driver))
+				assert.NoError(t, driver.Close(context.Background()), "close driver")
+				assert.Equal(t, strings.Join(scenario.expected, "\n")+"\n", buffer.String(), "entries")
+			},
+		)
+	}
+}
+
+func TestDriverColor(t *testing.T) {
+	var (
+		buffer = new(bytes.Buffer)
+		log    = l.New(New(buffer, WithClock(testClock), WithColor(true), WithStacktraceLevel(l.FATAL)))
+	)
+	log.Named("api").Error(context.Background(), "errorlog", l.Err(errors.New("failed")))
+	assert.Equal(t,
+		"\x1b[90m12:30:15.123\x1b[0m \x1b[31mERROR\x1b[0m \x1b[1mapi\x1b[0m errorlog"+strings.Repeat(" ", 32)+
+			" \x1b[36merror\x1b[0m=\x1b[31mfailed\x1b[0m\n",
+		buffer.String(),
+		"entry",
+	)
+}
+
+func TestDriverCaller(t *testing.T) {
+	var (
+		buffer = new(bytes.Buffer)
+		log    = l.New(New(buffer, WithClock(testClock), WithCaller(true)))
+	)
+	_, _, line, _ := runtime.Caller(0)
+	log.Info(context.Background(), "infolog")
+	assert.Equal(t,
+		fmt.Sprintf("12:30:15.123 INFO  consoledriver/driver_test.go:%d infolog\n", line+1),
+		buffer.String(),
+		"entry",
+	)
+}
+
+func TestDriverStacktrace(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	log := l.New(New(buffer, WithClock(testClock), WithStacktraceLevel(l.WARN)))
+	log.Warn(context.Background(), "warnlog", l.String("key", "value"))
+
+	lines := strings.Split(strings.TrimSuffix(buffer.String(), "\n"), "\n")
+	assert.True(t, len(lines) > 2, "entry lines %q", lines)
+	assert.Equal(t, "12:30:15.123 WARN  warnlog                                  key=value", lines[0], "entry")
+	assert.Equal(t, "    github.com/rjansen/l/consoledriver.TestDriverStacktrace", lines[1], "stack function")
+	assert.True(t, strings.HasPrefix(lines[2], "    \t"), "stack file %q", lines[2])
+}
+
+func TestDriverTerminal(t *testing.T) {
+	dir, err := ioutil.TempDir("", "consoledriver")
+	assert.NoError(t, err, "temp dir")
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "console.log")
+	fileDriver, err := Open(l.Out("file://"+path), WithClock(testClock))
+	assert.NoError(t, err, "open file")
+	assert.False(t, fileDriver.(*driver).color, "file color")
+	l.New(fileDriver).Info(context.Background(), "infolog")
+	assert.NoError(t, fileDriver.Close(context.Background()), "close driver")
+
+	data, err := ioutil.ReadFile(path)
+	assert.NoError(t, err, "file entries")
+	assert.Equal(t, "12:30:15.123 INFO  infolog\n", string(data), "file entry")
+
+	assert.False(t, New(new(bytes.Buffer)).(*driver).color, "buffer color")
+	assert.True(t, New(new(bytes.Buffer), WithColor(true)).(*driver).color, "forced color")
+	assert.False(t, isTerminal(os.NewFile(^uintptr(0), "invalid")), "invalid file")
+	if devNull, err := os.Open(os.DevNull); err == nil {
+		defer devNull.Close()
+		assert.True(t, isTerminal(devNull), "character device")
+		assert.False(t, New(devNull, WithColor(false)).(*driver).color, "disabled color")
+	}
+}
+
+func TestOpen(t *testing.T) {
+	for _, out := range []l.Out{l.STDOUT, l.STDERR} {
+		driver, err := Open(out)
+		assert.NoError(t, err, "open %s", out)
+		assert.NotNil(t, driver, "driver %s", out)
+		assert.NoError(t, driver.Close(context.Background()), "close %s", out)
+	}
+	driver, err := Open(l.Out("/invalid/path/app.log"))
+	assert.Error(t, err, "open error")
+	assert.Nil(t, driver, "driver instance")
+}
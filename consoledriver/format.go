@@ -0,0 +1,272 @@
+package consoledriver
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/rjansen/l"
+)
+
+// valueTimeLayout is the layout of time values, the same ISO8601 layout of zapdriver.NewLogger
+const valueTimeLayout = "2006-01-02T15:04:05.000Z0700"
+
+const (
+	resetColor   = "\x1b[0m"
+	boldColor    = "\x1b[1m"
+	faintColor   = "\x1b[90m"
+	redColor     = "\x1b[31m"
+	greenColor   = "\x1b[32m"
+	yellowColor  = "\x1b[33m"
+	blueColor    = "\x1b[34m"
+	magentaColor = "\x1b[35m"
+	cyanColor    = "\x1b[36m"
+	keyColor     = cyanColor
+	errorColor   = redColor
+)
+
+// levelText returns the upper case level padded to the width of the longest level
+func levelText(level l.Level) string {
+	switch level {
+	case l.INFO:
+		return "INFO "
+	case l.WARN:
+		return "WARN "
+	case l.TRACE, l.DEBUG, l.ERROR, l.PANIC, l.FATAL:
+		return strings.ToUpper(level.String())
+	default:
+		return fmt.Sprintf("%-5s", strings.ToUpper(level.String()))
+	}
+}
+
+func levelColor(level l.Level) string {
+	switch level {
+	case l.TRACE:
+		return magentaColor
+	case l.DEBUG:
+		return blueColor
+	case l.INFO:
+		return greenColor
+	case l.WARN:
+		return yellowColor
+	case l.ERROR:
+		return redColor
+	case l.PANIC, l.FATAL:
+		return boldColor + redColor
+	default:
+		return ""
+	}
+}
+
+// formatter appends key=value fields to a buffer and the multi-line errors to the blocks written after the entry,
+// the prefix holds the dotted path of the groups and namespaces of the fields
+type formatter struct {
+	buffer []byte
+	blocks []byte
+	prefix string
+	color  bool
+}
+
+func (format *formatter) appendColored(color string, text string) {
+	if format.color && color != "" {
+		format.buffer = append(format.buffer, color...)
+		format.buffer = append(format.buffer, text...)
+		format.buffer = append(format.buffer, resetColor...)
+		return
+	}
+	format.buffer = append(format.buffer, text...)
+}
+
+func (format *formatter) appendKey(name string) {
+	format.buffer = append(format.buffer, ' ')
+	format.appendColored(keyColor, format.prefix+name)
+	format.buffer = append(format.buffer, '=')
+}
+
+// appendBlock appends the text lines after the entry, indented and headed by the key when it is not empty
+func (format *formatter) appendBlock(color string, key string, text string) {
+	block := formatter{buffer: format.blocks, color: format.color}
+	for index, line := range strings.Split(text, "\n") {
+		block.buffer = append(block.buffer, blockIndent...)
+		if index == 0 && key != "" {
+			block.appendColored(keyColor, key)
+			block.buffer = append(block.buffer, ':', ' ')
+		}
+		block.appendColored(color, line)
+		block.buffer = append(block.buffer, '\n')
+	}
+	format.blocks = block.buffer
+}
+
+func (format *formatter) appendValues(values []l.Value) {
+	for _, value := range values {
+		format.appendValue(value.Resolve())
+	}
+}
+
+func (format *formatter) appendValue(value l.Value) {
+	switch value.Kind() {
+	case l.StringKind:
+		format.appendKey(value.Name())
+		format.buffer = appendText(format.buffer, value.AsString())
+	case l.Int64Kind:
+		format.appendKey(value.Name())
+		format.buffer = strconv.AppendInt(format.buffer, value.AsInt64(), 10)
+	case l.Uint64Kind:
+		format.appendKey(value.Name())
+		format.buffer = strconv.AppendUint(format.buffer, value.AsUint64(), 10)
+	case l.Float64Kind:
+		format.appendKey(value.Name())
+		format.buffer = strconv.AppendFloat(format.buffer, value.AsFloat64(), 'f', -1, 64)
+	case l.BoolKind:
+		format.appendKey(value.Name())
+		format.buffer = strconv.AppendBool(format.buffer, value.AsBool())
+	case l.TimeKind:
+		format.appendKey(value.Name())
+		format.buffer = value.AsTime().AppendFormat(format.buffer, valueTimeLayout)
+	case l.DurationKind:
+		format.appendKey(value.Name())
+		format.buffer = append(format.buffer, value.AsDuration().String()...)
+	case l.ErrorKind:
+		if err := value.AsError(); err != nil {
+			format.appendError(value.Name(), err)
+		}
+	case l.BytesKind:
+		format.appendKey(value.Name())
+		format.buffer = append(format.buffer, base64.StdEncoding.EncodeToString(value.AsBytes())...)
+	case l.StringsKind:
+		format.appendKey(value.Name())
+		format.buffer = appendStrings(format.buffer, value.AsStrings())
+	case l.GroupKind:
+		format.appendGroup(value.Name(), value.AsGroup())
+	case l.NamespaceKind:
+		format.prefix += value.Name() + "."
+	default:
+		format.appendAny(value.Name(), value.Any())
+	}
+}
+
+// appendError appends the first line of the error as a field,
+// the whole error, with the %+v details of errors like github.com/pkg/errors, follows the entry when it has several lines
+func (format *formatter) appendError(name string, err error) {
+	message := err.Error()
+	detail := message
+	if _, ok := err.(fmt.Formatter); ok {
+		detail = fmt.Sprintf("%+v", err)
+	}
+	first := message
+	if index := strings.IndexByte(message, '\n'); index >= 0 {
+		first = message[:index]
+	}
+	format.appendKey(name)
+	if format.color {
+		format.buffer = append(format.buffer, errorColor...)
+		format.buffer = appendText(format.buffer, first)
+		format.buffer = append(format.buffer, resetColor...)
+	} else {
+		format.buffer = appendText(format.buffer, first)
+	}
+	if strings.IndexByte(detail, '\n') >= 0 {
+		format.appendBlock(errorColor, format.prefix+name, strings.TrimRight(detail, "\n"))
+	}
+}
+
+func (format *formatter) appendGroup(name string, values []l.Value) {
+	if len(values) == 0 {
+		return
+	}
+	if name == "" {
+		format.appendValues(values)
+		return
+	}
+	prefix := format.prefix
+	format.prefix += name + "."
+	format.appendValues(values)
+	format.prefix = prefix
+}
+
+// appendAny formats the common types like their l.Value and any other type with the fmt %+v verb
+func (format *formatter) appendAny(name string, value interface{}) {
+	switch value := value.(type) {
+	case string:
+		format.appendValue(l.String(name, value))
+	case int:
+		format.appendValue(l.Int64(name, int64(value)))
+	case int32:
+		format.appendValue(l.Int64(name, int64(value)))
+	case int64:
+		format.appendValue(l.Int64(name, value))
+	case uint:
+		format.appendValue(l.Uint64(name, uint64(value)))
+	case uint32:
+		format.appendValue(l.Uint64(name, uint64(value)))
+	case uint64:
+		format.appendValue(l.Uint64(name, value))
+	case float32:
+		format.appendKey(name)
+		format.buffer = strconv.AppendFloat(format.buffer, float64(value), 'f', -1, 32)
+	case float64:
+		format.appendValue(l.Float64(name, value))
+	case bool:
+		format.appendValue(l.Bool(name, value))
+	case time.Time:
+		format.appendValue(l.Time(name, value))
+	case time.Duration:
+		format.appendValue(l.Duration(name, value))
+	case error:
+		format.appendValue(l.NamedErr(name, value))
+	case []byte:
+		format.appendValue(l.Bytes(name, value))
+	case []string:
+		format.appendValue(l.Strings(name, value))
+	case fmt.Stringer:
+		format.appendValue(l.String(name, value.String()))
+	default:
+		format.appendKey(name)
+		format.buffer = appendText(format.buffer, fmt.Sprintf("%+v", value))
+	}
+}
+
+func appendStrings(buffer []byte, values []string) []byte {
+	buffer = append(buffer, '[')
+	for index, value := range values {
+		if index > 0 {
+			buffer = append(buffer, ',')
+		}
+		if strings.ContainsAny(value, ",[]") {
+			buffer = strconv.AppendQuote(buffer, value)
+		} else {
+			buffer = appendText(buffer, value)
+		}
+	}
+	return append(buffer, ']')
+}
+
+// appendText appends the text as is, or quoted when it is empty or has spaces, quotes, equal signs or non printable characters
+func appendText(buffer []byte, text string) []byte {
+	if needsQuote(text) {
+		return strconv.AppendQuote(buffer, text)
+	}
+	return append(buffer, text...)
+}
+
+func needsQuote(text string) bool {
+	if text == "" {
+		return true
+	}
+	for index := 0; index < len(text); {
+		char, size := utf8.DecodeRuneInString(text[index:])
+		if char == utf8.RuneError && size == 1 {
+			return true
+		}
+		if char == ' ' || char == '=' || char == '"' || !unicode.IsPrint(char) {
+			return true
+		}
+		index += size
+	}
+	return false
+}
@@ -0,0 +1,128 @@
+package consoledriver
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/rjansen/l"
+	"github.com/stretchr/testify/assert"
+)
+
+type testStringer struct{}
+
+func (testStringer) String() string {
+	return "stringer value"
+}
+
+type testDetailedError struct{}
+
+func (testDetailedError) Error() string {
+	return "detailed failure"
+}
+
+func (err testDetailedError) Format(state fmt.State, verb rune) {
+	if verb == 'v' && state.Flag('+') {
+		fmt.Fprint(state, "detailed failure\nmain.main\n\tmain.go:10")
+		return
+	}
+	fmt.Fprint(state, err.Error())
+}
+
+type testFormatter struct {
+	name           string
+	values         []l.Value
+	expected       string
+	expectedBlocks string
+}
+
+func TestFormatter(test *testing.T) {
+	scenarios := []testFormatter{
+		{
+			name: "Formats typed values",
+			values: []l.Value{
+				l.String("string", "value"),
+				l.Int("int", -1),
+				l.Uint64("uint", 1),
+				l.Float64("float", 0.5),
+				l.Float64("nan", math.NaN()),
+				l.Bool("bool", true),
+				l.Time("time", time.Date(2019, 10, 1, 12, 0, 0, 0, time.UTC)),
+				l.Duration("duration", time.Second),
+				l.Bytes("bytes", []byte("bytes1")),
+				l.Strings("strings", []string{"a", "b c", "d,e"}),
+				l.Lazy("lazy", func() interface{} { return "lazy1" }),
+			},
+			expected: ` string=value int=-1 uint=1 float=0.5 nan=NaN bool=true time=2019-10-01T12:00:00.000Z duration=1s` +
+				` bytes=Ynl0ZXMx strings=[a,"b c","d,e"] lazy=lazy1`,
+		},
+		{
+			name: "Quotes strings",
+			values: []l.Value{
+				l.String("empty", ""),
+				l.String("space", "a b"),
+				l.String("equal", "a=b"),
+				l.String("quote", `a"b`),
+				l.String("newline", "a\nb"),
+				l.String("invalid", "a\xffb"),
+				l.String("unicode", "ação"),
+			},
+			expected: ` empty="" space="a b" equal="a=b" quote="a\"b" newline="a\nb" invalid="a\xffb" unicode=ação`,
+		},
+		{
+			name: "Formats any values",
+			values: []l.Value{
+				l.NewValue("int", 1),
+				l.NewValue("float32", float32(0.5)),
+				l.NewValue("stringer", testStringer{}),
+				l.NewValue("map", map[string]int{"a": 1}),
+				l.NewValue("nil", nil),
+				l.NewValue("err", errors.New("failed")),
+			},
+			expected: ` int=1 float32=0.5 stringer="stringer value" map=map[a:1] nil=<nil> err=failed`,
+		},
+		{
+			name: "Formats groups and namespaces as dotted keys",
+			values: []l.Value{
+				l.Group("http", l.String("method", "GET"), l.Group("response", l.Int("status", 200))),
+				l.Group("empty"),
+				l.Group("", l.Bool("inline", true)),
+				l.Namespace("user"),
+				l.String("id", "user1"),
+			},
+			expected: ` http.method=GET http.response.status=200 inline=true user.id=user1`,
+		},
+		{
+			name: "Formats errors with details after the entry",
+			values: []l.Value{
+				l.Err(nil),
+				l.Group("db", l.NamedErr("cause", testDetailedError{})),
+			},
+			expected:       ` db.cause="detailed failure"`,
+			expectedBlocks: "    db.cause: detailed failure\n    main.main\n    \tmain.go:10\n",
+		},
+	}
+
+	for index, scenario := range scenarios {
+		test.Run(
+			fmt.Sprintf("[%d]-%s", index, scenario.name),
+			func(t *testing.T) {
+				var format formatter
+				format.appendValues(scenario.values)
+				assert.Equal(t, scenario.expected, string(format.buffer), "fields")
+				assert.Equal(t, scenario.expectedBlocks, string(format.blocks), "blocks")
+			},
+		)
+	}
+}
+
+func TestLevelText(t *testing.T) {
+	for _, level := range []l.Level{l.TRACE, l.DEBUG, l.INFO, l.WARN, l.ERROR, l.PANIC, l.FATAL} {
+		assert.Len(t, levelText(level), 5, "level %s text", level)
+		assert.NotEmpty(t, levelColor(level), "level %s color", level)
+	}
+	assert.Equal(t, "X    ", levelText(l.Level("x")), "unknown level text")
+	assert.Empty(t, levelColor(l.Level("x")), "unknown level color")
+}
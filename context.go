@@ -0,0 +1,62 @@
+package l
+
+import (
+	"context"
+)
+
+type contextValuesKey struct{}
+
+// WithValues returns a copy of ctx carrying the provided values, they are merged into every log call made with the returned context
+func WithValues(ctx context.Context, values ...Value) context.Context {
+	if len(values) == 0 {
+		return ctx
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	var (
+		current = ValuesFromContext(ctx)
+		merged  = make([]Value, 0, len(current)+len(values))
+	)
+	merged = append(merged, current...)
+	merged = append(merged, values...)
+	return context.WithValue(ctx, contextValuesKey{}, merged)
+}
+
+// ValuesFromContext returns the values carried by ctx or nil when there is none
+func ValuesFromContext(ctx context.Context) []Value {
+	if ctx == nil {
+		return nil
+	}
+	values, _ := ctx.Value(contextValuesKey{}).([]Value)
+	return values
+}
+
+func mergeContextValues(ctx context.Context, values []Value) []Value {
+	contextValues := ValuesFromContext(ctx)
+	if len(contextValues) == 0 {
+		return values
+	}
+	merged := make([]Value, 0, len(contextValues)+len(values))
+	merged = append(merged, contextValues...)
+	return append(merged, values...)
+}
+
+// ContextWriter is implemented by a LogWriter that uses the context and the call site of the log call,
+// the Logger prefers it over CallerWriter and LogWriter
+type ContextWriter interface {
+	LogWriter
+	WriteContext(context.Context, Caller, ...Value)
+}
+
+// writeContext writes to the writer with the context and the call site it uses, like the Logger does
+func writeContext(writer LogWriter, ctx context.Context, caller Caller, values []Value) {
+	switch writer := writer.(type) {
+	case ContextWriter:
+		writer.WriteContext(ctx, caller, values...)
+	case CallerWriter:
+		writer.WriteCaller(caller, values...)
+	default:
+		writer.Write(values...)
+	}
+}
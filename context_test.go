@@ -0,0 +1,94 @@
+package l
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type testContextValues struct {
+	name     string
+	ctx      context.Context
+	values   [][]Value
+	expected []Value
+}
+
+func TestContextValues(test *testing.T) {
+	scenarios := []testContextValues{
+		{
+			name:     "Returns nil values from a nil context",
+			ctx:      nil,
+			expected: nil,
+		},
+		{
+			name:     "Returns nil values from an empty context",
+			ctx:      context.Background(),
+			expected: nil,
+		},
+		{
+			name: "Returns values from a context",
+			ctx:  context.Background(),
+			values: [][]Value{
+				{NewValue("requestid", "request1")},
+			},
+			expected: []Value{
+				NewValue("requestid", "request1"),
+			},
+		},
+		{
+			name: "Returns values appended through nested contexts",
+			ctx:  context.Background(),
+			values: [][]Value{
+				{NewValue("requestid", "request1")},
+				{},
+				{NewValue("tenant", "tenant1"), NewValue("user", "user1")},
+			},
+			expected: []Value{
+				NewValue("requestid", "request1"),
+				NewValue("tenant", "tenant1"),
+				NewValue("user", "user1"),
+			},
+		},
+	}
+
+	for index, scenario := range scenarios {
+		test.Run(
+			fmt.Sprintf("[%d]-%s", index, scenario.name),
+			func(t *testing.T) {
+				ctx := scenario.ctx
+				for _, values := range scenario.values {
+					ctx = WithValues(ctx, values...)
+				}
+				assert.Equal(t, scenario.expected, ValuesFromContext(ctx), "context values")
+			},
+		)
+	}
+}
+
+func TestContextValuesIsolation(t *testing.T) {
+	var (
+		parent = WithValues(context.Background(), NewValue("requestid", "request1"))
+		first  = WithValues(parent, NewValue("user", "user1"))
+		second = WithValues(parent, NewValue("user", "user2"))
+	)
+	assert.Equal(t, []Value{NewValue("requestid", "request1")}, ValuesFromContext(parent))
+	assert.Equal(t, []Value{NewValue("requestid", "request1"), NewValue("user", "user1")}, ValuesFromContext(first))
+	assert.Equal(t, []Value{NewValue("requestid", "request1"), NewValue("user", "user2")}, ValuesFromContext(second))
+}
+
+func TestLoggerContextValues(t *testing.T) {
+	var (
+		driver = newMockDriver()
+		writer = newMockLogWriter()
+		ctx    = WithValues(context.Background(), NewValue("requestid", "request1"))
+		values = []Value{NewValue("key", "value")}
+	)
+	writer.On("Write", mock.AnythingOfType("[]l.Value")).Once()
+	driver.On("Log", INFO, "infolog").Return(writer).Once()
+
+	New(driver).Info(ctx, "infolog", values...)
+	writer.AssertCalled(t, "Write", []Value{NewValue("requestid", "request1"), NewValue("key", "value")})
+}
@@ -0,0 +1,143 @@
+package l
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync/atomic"
+)
+
+// ErrorHandler receives the failures of the drivers and outputs which can not be returned to a log call,
+// like an entry which could not be encoded or written, it must not log to the failing output
+type ErrorHandler func(error)
+
+var errorHandler atomic.Value
+
+func defaultErrorHandler(err error) {
+	fmt.Fprintln(os.Stderr, err)
+}
+
+// SetErrorHandler replaces the ErrorHandler, nil restores the default one which writes the errors to os.Stderr
+func SetErrorHandler(handler ErrorHandler) {
+	if handler == nil {
+		handler = defaultErrorHandler
+	}
+	errorHandler.Store(handler)
+}
+
+// HandleError reports the error to the ErrorHandler, drivers call it for the failures of their outputs
+func HandleError(err error) {
+	if err == nil {
+		return
+	}
+	if handler, ok := errorHandler.Load().(ErrorHandler); ok {
+		handler(err)
+		return
+	}
+	defaultErrorHandler(err)
+}
+
+// WriteEntry writes an encoded entry and counts it on Stats, a failed write is reported to the ErrorHandler
+// and the entry is written to os.Stderr instead, drivers call it with their outputs
+func WriteEntry(writer io.Writer, level Level, entry []byte) (int, error) {
+	written, err := writer.Write(entry)
+	if err == nil {
+		countWritten(level, written)
+		return written, nil
+	}
+	countFailed(level)
+	HandleError(fmt.Errorf("err_write{Level=%q, Message='%w'}", level, err))
+	if !IsStderr(writer) {
+		_, _ = os.Stderr.Write(entry)
+	}
+	return written, err
+}
+
+// FailEntry counts an entry which could not be encoded on Stats and reports the error to the ErrorHandler,
+// drivers call it for the entries which never reach WriteEntry
+func FailEntry(level Level, err error) {
+	countFailed(level)
+	HandleError(fmt.Errorf("err_encode{Level=%q, Message='%w'}", level, err))
+}
+
+// LevelStats counts the entries of a level
+type LevelStats struct {
+	// Written is the number of entries written to their outputs
+	Written uint64
+	// Dropped is the number of entries dropped before their outputs, like by the overflow policy of the async driver
+	Dropped uint64
+	// Failed is the number of entries which could not be encoded or written
+	Failed uint64
+	// Bytes is the size of the written entries
+	Bytes uint64
+}
+
+// Statistics is a snapshot of the entries counted by Stats for every level
+type Statistics map[Level]LevelStats
+
+// Total sums the counters of all the levels
+func (statistics Statistics) Total() LevelStats {
+	var total LevelStats
+	for _, level := range statistics {
+		total.Written += level.Written
+		total.Dropped += level.Dropped
+		total.Failed += level.Failed
+		total.Bytes += level.Bytes
+	}
+	return total
+}
+
+type levelCounters struct {
+	written uint64
+	dropped uint64
+	failed  uint64
+	bytes   uint64
+}
+
+// counters holds the counters of the levels in severity order, from TRACE to FATAL
+var counters [7]levelCounters
+
+var counterLevels = [len(counters)]Level{TRACE, DEBUG, INFO, WARN, ERROR, PANIC, FATAL}
+
+func levelCountersOf(level Level) *levelCounters {
+	severity, ok := level.severity()
+	if !ok {
+		return nil
+	}
+	return &counters[severity]
+}
+
+func countWritten(level Level, bytes int) {
+	if counter := levelCountersOf(level); counter != nil {
+		atomic.AddUint64(&counter.written, 1)
+		atomic.AddUint64(&counter.bytes, uint64(bytes))
+	}
+}
+
+func countDropped(level Level) {
+	if counter := levelCountersOf(level); counter != nil {
+		atomic.AddUint64(&counter.dropped, 1)
+	}
+}
+
+func countFailed(level Level) {
+	if counter := levelCountersOf(level); counter != nil {
+		atomic.AddUint64(&counter.failed, 1)
+	}
+}
+
+// Stats returns the counters of the entries handled by the loggers of zapdriver.NewLogger, the async driver
+// and the drivers writing with WriteEntry since the process started
+func Stats() Statistics {
+	statistics := make(Statistics, len(counters))
+	for severity := range counters {
+		counter := &counters[severity]
+		statistics[counterLevels[severity]] = LevelStats{
+			Written: atomic.LoadUint64(&counter.written),
+			Dropped: atomic.LoadUint64(&counter.dropped),
+			Failed:  atomic.LoadUint64(&counter.failed),
+			Bytes:   atomic.LoadUint64(&counter.bytes),
+		}
+	}
+	return statistics
+}
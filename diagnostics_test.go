@@ -0,0 +1,204 @@
+package l
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testErrors records the errors reported to the ErrorHandler
+type testErrors struct {
+	mutex  sync.Mutex
+	errors []error
+}
+
+func (handler *testErrors) handle(err error) {
+	handler.mutex.Lock()
+	handler.errors = append(handler.errors, err)
+	handler.mutex.Unlock()
+}
+
+func (handler *testErrors) Messages() []string {
+	handler.mutex.Lock()
+	defer handler.mutex.Unlock()
+	var messages []string
+	for _, err := range handler.errors {
+		messages = append(messages, err.Error())
+	}
+	return messages
+}
+
+func captureErrors() (*testErrors, func()) {
+	handler := new(testErrors)
+	SetErrorHandler(handler.handle)
+	return handler, func() { SetErrorHandler(nil) }
+}
+
+// captureStderr replaces os.Stderr with a file and returns a function restoring it which returns the file content
+func captureStderr(t *testing.T) func() string {
+	file, err := ioutil.TempFile("", "stderr")
+	assert.NoError(t, err, "stderr file")
+	stderr := os.Stderr
+	os.Stderr = file
+	return func() string {
+		os.Stderr = stderr
+		assert.NoError(t, file.Close(), "close stderr file")
+		defer os.Remove(file.Name())
+		data, err := ioutil.ReadFile(file.Name())
+		assert.NoError(t, err, "stderr content")
+		return string(data)
+	}
+}
+
+// testFailingWriter fails every write
+type testFailingWriter struct {
+	err error
+}
+
+func (writer testFailingWriter) Write([]byte) (int, error) {
+	return 0, writer.err
+}
+
+func (writer testFailingWriter) Sync() error {
+	return nil
+}
+
+// testStderrWriter fails every write like an output of os.Stderr
+type testStderrWriter struct {
+	testFailingWriter
+}
+
+func (testStderrWriter) Stderr() bool {
+	return true
+}
+
+func statsDelta(before Statistics, after Statistics) Statistics {
+	delta := make(Statistics, len(after))
+	for level, stats := range after {
+		delta[level] = LevelStats{
+			Written: stats.Written - before[level].Written,
+			Dropped: stats.Dropped - before[level].Dropped,
+			Failed:  stats.Failed - before[level].Failed,
+			Bytes:   stats.Bytes - before[level].Bytes,
+		}
+	}
+	return delta
+}
+
+func TestErrorHandler(t *testing.T) {
+	handler, restore := captureErrors()
+	HandleError(errors.New("err_first"))
+	HandleError(nil)
+	HandleError(errors.New("err_second"))
+	restore()
+	assert.Equal(t, []string{"err_first", "err_second"}, handler.Messages(), "handled errors")
+
+	stderr := captureStderr(t)
+	HandleError(errors.New("err_default"))
+	assert.Equal(t, "err_default\n", stderr(), "default handler")
+}
+
+type testWriteEntry struct {
+	name     string
+	level    Level
+	writer   func(*bytes.Buffer) io.Writer
+	expected LevelStats
+	stderr   string
+	errors   []string
+}
+
+func TestWriteEntry(test *testing.T) {
+	scenarios := []testWriteEntry{
+		{
+			name:     "Counts a written entry",
+			level:    INFO,
+			writer:   func(buffer *bytes.Buffer) io.Writer { return buffer },
+			expected: LevelStats{Written: 1, Bytes: 6},
+		},
+		{
+			name:     "Counts a failed entry and writes it to stderr",
+			level:    ERROR,
+			writer:   func(*bytes.Buffer) io.Writer { return testFailingWriter{err: errors.New("disk full")} },
+			expected: LevelStats{Failed: 1},
+			stderr:   "entry\n",
+			errors:   []string{`err_write{Level="error", Message='disk full'}`},
+		},
+		{
+			name:  "Counts a failed entry of stderr without writing it to stderr again",
+			level: ERROR,
+			writer: func(*bytes.Buffer) io.Writer {
+				return testStderrWriter{testFailingWriter: testFailingWriter{err: errors.New("broken pipe")}}
+			},
+			expected: LevelStats{Failed: 1},
+			errors:   []string{`err_write{Level="error", Message='broken pipe'}`},
+		},
+		{
+			name:     "Does not count an unknown level",
+			level:    Level("verbose"),
+			writer:   func(buffer *bytes.Buffer) io.Writer { return buffer },
+			expected: LevelStats{},
+		},
+	}
+
+	for index, scenario := range scenarios {
+		test.Run(
+			fmt.Sprintf("[%d]-%s", index, scenario.name),
+			func(t *testing.T) {
+				var (
+					buffer          = new(bytes.Buffer)
+					handler, reset  = captureErrors()
+					stderr          = captureStderr(t)
+					before          = Stats()
+					written, errOut = WriteEntry(scenario.writer(buffer), scenario.level, []byte("entry\n"))
+					delta           = statsDelta(before, Stats())
+				)
+				reset()
+				assert.Equal(t, scenario.stderr, stderr(), "stderr")
+				assert.Equal(t, scenario.errors, handler.Messages(), "handled errors")
+				assert.Equal(t, scenario.expected, delta.Total(), "stats")
+				if scenario.errors == nil {
+					assert.NoError(t, errOut, "write error")
+					assert.Equal(t, 6, written, "written bytes")
+					assert.Equal(t, scenario.expected, delta[scenario.level], "level stats")
+				} else {
+					assert.Error(t, errOut, "write error")
+				}
+			},
+		)
+	}
+}
+
+func TestStats(t *testing.T) {
+	stats := Stats()
+	assert.Len(t, stats, 7, "levels")
+	for _, level := range []Level{TRACE, DEBUG, INFO, WARN, ERROR, PANIC, FATAL} {
+		assert.Contains(t, stats, level, "level %s", level)
+	}
+	assert.Equal(
+		t,
+		LevelStats{Written: 3, Dropped: 2, Failed: 1, Bytes: 30},
+		Statistics{
+			INFO:  {Written: 1, Dropped: 2, Bytes: 10},
+			ERROR: {Written: 2, Failed: 1, Bytes: 20},
+		}.Total(),
+		"total",
+	)
+}
+
+func TestFailEntry(t *testing.T) {
+	handler, reset := captureErrors()
+	defer reset()
+
+	before := Stats()
+	FailEntry(WARN, errors.New("unsupported value"))
+	delta := statsDelta(before, Stats())
+	assert.Equal(t, LevelStats{Failed: 1}, delta[WARN], "level stats")
+	assert.Equal(t, []string{`err_encode{Level="warn", Message='unsupported value'}`}, handler.Messages(), "handled errors")
+}
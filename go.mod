@@ -1,10 +1,18 @@
 module github.com/rjansen/l
 
-go 1.12
+go 1.21
 
 require (
 	github.com/stretchr/testify v1.4.0
+	go.uber.org/zap v1.10.0
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.0 // indirect
+	github.com/pkg/errors v0.9.1 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	github.com/stretchr/objx v0.1.0 // indirect
 	go.uber.org/atomic v1.4.0 // indirect
 	go.uber.org/multierr v1.1.0 // indirect
-	go.uber.org/zap v1.10.0
+	gopkg.in/yaml.v2 v2.2.2 // indirect
 )
@@ -0,0 +1,29 @@
+package driverutil
+
+import (
+	"sync"
+)
+
+// maxBufferSize is the capacity above which a buffer is dropped to keep the pool memory bounded
+const maxBufferSize = 64 * 1024
+
+var bufferPool = sync.Pool{
+	New: func() interface{} {
+		buffer := make([]byte, 0, 1024)
+		return &buffer
+	},
+}
+
+// GetBuffer returns an empty buffer from the pool
+func GetBuffer() *[]byte {
+	buffer := bufferPool.Get().(*[]byte)
+	*buffer = (*buffer)[:0]
+	return buffer
+}
+
+// PutBuffer returns the buffer to the pool, the large ones are dropped
+func PutBuffer(buffer *[]byte) {
+	if cap(*buffer) <= maxBufferSize {
+		bufferPool.Put(buffer)
+	}
+}
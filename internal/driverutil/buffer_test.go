@@ -0,0 +1,20 @@
+package driverutil
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuffer(t *testing.T) {
+	buffer := GetBuffer()
+	*buffer = append(*buffer, "entry"...)
+	PutBuffer(buffer)
+	assert.Empty(t, *GetBuffer(), "pooled buffer")
+
+	large := make([]byte, 0, maxBufferSize+1)
+	PutBuffer(&large)
+	for index := 0; index < 10; index++ {
+		assert.True(t, cap(*GetBuffer()) <= maxBufferSize, "dropped large buffer")
+	}
+}
@@ -0,0 +1,57 @@
+// Package driverutil holds the options, sink, caller and stacktrace shared by the drivers
+// which encode their entries with the standard library
+package driverutil
+
+import (
+	"time"
+
+	"github.com/rjansen/l"
+)
+
+// Options are the settings shared by the drivers, a driver embeds them on its own options
+type Options struct {
+	Level           l.Level
+	Caller          bool
+	StacktraceLevel l.Level
+	Now             func() time.Time
+}
+
+// NewOptions returns the default Options: every level, no caller and stacktraces from ERROR
+func NewOptions() Options {
+	return Options{
+		Level:           l.TRACE,
+		StacktraceLevel: l.ERROR,
+		Now:             time.Now,
+	}
+}
+
+// Option customizes the shared Options, a driver adapts it to its own option type
+type Option func(*Options)
+
+// WithLevel sets the lowest level written by the driver
+func WithLevel(level l.Level) Option {
+	return func(options *Options) {
+		options.Level = level
+	}
+}
+
+// WithCaller enables the caller file and line on the entries
+func WithCaller(enabled bool) Option {
+	return func(options *Options) {
+		options.Caller = enabled
+	}
+}
+
+// WithStacktraceLevel sets the level from which entries carry a stacktrace
+func WithStacktraceLevel(level l.Level) Option {
+	return func(options *Options) {
+		options.StacktraceLevel = level
+	}
+}
+
+// WithClock replaces the time source of the entries
+func WithClock(now func() time.Time) Option {
+	return func(options *Options) {
+		options.Now = now
+	}
+}
@@ -0,0 +1,62 @@
+package driverutil
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/rjansen/l"
+	"github.com/stretchr/testify/assert"
+)
+
+var testTime = time.Date(2019, 10, 1, 12, 30, 15, 123000000, time.UTC)
+
+func testClock() time.Time {
+	return testTime
+}
+
+type testOptions struct {
+	name     string
+	options  []Option
+	expected Options
+}
+
+func TestOptions(test *testing.T) {
+	scenarios := []testOptions{
+		{
+			name:     "Writes every level without caller and with stacktraces from ERROR by default",
+			expected: Options{Level: l.TRACE, StacktraceLevel: l.ERROR},
+		},
+		{
+			name: "Applies the options",
+			options: []Option{
+				WithLevel(l.INFO),
+				WithCaller(true),
+				WithStacktraceLevel(l.WARN),
+				WithClock(testClock),
+			},
+			expected: Options{Level: l.INFO, Caller: true, StacktraceLevel: l.WARN},
+		},
+	}
+
+	for index, scenario := range scenarios {
+		test.Run(
+			fmt.Sprintf("[%d]-%s", index, scenario.name),
+			func(t *testing.T) {
+				options := NewOptions()
+				for _, option := range scenario.options {
+					option(&options)
+				}
+				assert.NotNil(t, options.Now, "time source")
+				options.Now = nil
+				assert.Equal(t, scenario.expected, options, "options")
+			},
+		)
+	}
+}
+
+func TestWithClock(t *testing.T) {
+	options := NewOptions()
+	WithClock(testClock)(&options)
+	assert.Equal(t, testTime, options.Now(), "entry time")
+}
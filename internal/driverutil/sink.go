@@ -0,0 +1,146 @@
+package driverutil
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/rjansen/l"
+)
+
+// output is a writer of the Sink with the level range of its l.Destination
+type output struct {
+	writer io.Writer
+	closer io.Closer
+	level  l.Level
+	below  l.Level
+}
+
+func (output output) enabled(level l.Level) bool {
+	return (output.level == "" || level.Enabled(output.level)) &&
+		(output.below == "" || !level.Enabled(output.below))
+}
+
+// sync commits the entries of a file, the standard streams are not synced because fsync fails on terminals and pipes
+func (output output) sync() error {
+	if output.writer == os.Stdout || output.writer == os.Stderr {
+		return nil
+	}
+	if syncer, ok := output.writer.(interface{ Sync() error }); ok {
+		return syncer.Sync()
+	}
+	return nil
+}
+
+// Sink serializes the entries written to the underlying writers, one Write call per entry and writer
+type Sink struct {
+	mutex   sync.Mutex
+	outputs []output
+	closed  bool
+}
+
+// NewSink creates a Sink which writes to the writer, the writer is not closed by the Sink
+func NewSink(writer io.Writer) *Sink {
+	return &Sink{outputs: []output{{writer: writer}}}
+}
+
+// Open creates a Sink which writes to every destination of the output with its level range.
+// A file is opened with l.OpenDestination, so it is rotated and opened again by l.Reopen, and is closed by Close.
+// The encoding is chosen by the driver, so a destination with an encoding is rejected
+func Open(out l.Out) (*Sink, error) {
+	destinations, err := out.Destinations()
+	if err != nil {
+		return nil, err
+	}
+	sink := &Sink{outputs: make([]output, 0, len(destinations))}
+	for _, destination := range destinations {
+		if destination.Encoding != "" {
+			_ = sink.Close()
+			return nil, fmt.Errorf("err_invalid_out{Out=%q, Message='encoding is chosen by the driver'}", destination)
+		}
+		opened := output{level: destination.Level, below: destination.Below}
+		switch destination.Out {
+		case l.STDOUT:
+			opened.writer = os.Stdout
+		case l.STDERR:
+			opened.writer = os.Stderr
+		default:
+			file, err := l.OpenDestination(destination)
+			if err != nil {
+				_ = sink.Close()
+				return nil, err
+			}
+			opened.writer, opened.closer = file, file
+		}
+		sink.outputs = append(sink.outputs, opened)
+	}
+	return sink, nil
+}
+
+// Writers returns the underlying writers
+func (sink *Sink) Writers() []io.Writer {
+	writers := make([]io.Writer, len(sink.outputs))
+	for index, output := range sink.outputs {
+		writers[index] = output.writer
+	}
+	return writers
+}
+
+// Enabled reports whether any writer takes the entries of the level
+func (sink *Sink) Enabled(level l.Level) bool {
+	for _, output := range sink.outputs {
+		if output.enabled(level) {
+			return true
+		}
+	}
+	return false
+}
+
+// Write writes the entry to the writers taking its level and counts it on l.Stats,
+// a failed entry is reported to the l.ErrorHandler and written to os.Stderr
+func (sink *Sink) Write(level l.Level, entry []byte) {
+	sink.mutex.Lock()
+	for _, output := range sink.outputs {
+		if output.enabled(level) {
+			_, _ = l.WriteEntry(output.writer, level, entry)
+		}
+	}
+	sink.mutex.Unlock()
+}
+
+// Sync commits the written entries, a closed Sink has nothing to sync
+func (sink *Sink) Sync() error {
+	sink.mutex.Lock()
+	defer sink.mutex.Unlock()
+	if sink.closed {
+		return nil
+	}
+	return sink.sync()
+}
+
+func (sink *Sink) sync() error {
+	var errs []error
+	for _, output := range sink.outputs {
+		errs = append(errs, output.sync())
+	}
+	return errors.Join(errs...)
+}
+
+// Close syncs the writers and closes the files opened by Open, closing it again does nothing
+func (sink *Sink) Close() error {
+	sink.mutex.Lock()
+	defer sink.mutex.Unlock()
+	if sink.closed {
+		return nil
+	}
+	sink.closed = true
+	errs := []error{sink.sync()}
+	for _, output := range sink.outputs {
+		if output.closer != nil {
+			errs = append(errs, output.closer.Close())
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,185 @@
+package driverutil
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/rjansen/l"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOpen(t *testing.T) {
+	for _, out := range []l.Out{l.STDOUT, l.STDERR, l.NewSplitOut(l.STDOUT, l.STDERR, l.ERROR)} {
+		sink, err := Open(out)
+		assert.NoError(t, err, "open %s", out)
+		assert.NotNil(t, sink, "sink %s", out)
+		assert.NoError(t, sink.Close(), "close %s", out)
+	}
+	for _, out := range []l.Out{
+		l.Out("/invalid/path/app.log"),
+		l.Out("http://localhost/app.log"),
+		l.Out("stdout?level=invalid"),
+		l.Out("stdout?encoding=console"),
+	} {
+		sink, err := Open(out)
+		assert.Error(t, err, "open error %s", out)
+		assert.Nil(t, sink, "sink instance %s", out)
+	}
+}
+
+func TestOpenDestinations(t *testing.T) {
+	dir, err := ioutil.TempDir("", "driverutil")
+	assert.NoError(t, err, "temp dir")
+	defer os.RemoveAll(dir)
+
+	var (
+		infoPath  = filepath.Join(dir, "info.log")
+		errorPath = filepath.Join(dir, "error.log")
+	)
+	assert.NoError(t, ioutil.WriteFile(infoPath, []byte("entry0\n"), 0644), "existing entry")
+	sink, err := Open(l.NewSplitOut(l.Out("file://"+infoPath), l.Out(errorPath), l.ERROR))
+	assert.NoError(t, err, "open destinations")
+	assert.Len(t, sink.Writers(), 2, "writers")
+	assert.True(t, sink.Enabled(l.TRACE), "trace enabled")
+	sink.Write(l.INFO, []byte("entry1\n"))
+	sink.Write(l.ERROR, []byte("entry2\n"))
+	assert.NoError(t, sink.Close(), "close sink")
+
+	for path, expected := range map[string]string{infoPath: "entry0\nentry1\n", errorPath: "entry2\n"} {
+		data, err := ioutil.ReadFile(path)
+		assert.NoError(t, err, "file entries %s", path)
+		assert.Equal(t, expected, string(data), "file entries %s", path)
+	}
+	info, err := os.Stat(errorPath)
+	assert.NoError(t, err, "created file")
+	assert.Equal(t, os.FileMode(0644), info.Mode().Perm(), "created file mode")
+
+	sink, err = Open(l.Out(errorPath + "?level=error"))
+	assert.NoError(t, err, "open leveled destination")
+	assert.False(t, sink.Enabled(l.WARN), "warn enabled")
+	assert.True(t, sink.Enabled(l.FATAL), "fatal enabled")
+	assert.NoError(t, sink.Close(), "close leveled sink")
+}
+
+func TestOpenReopen(t *testing.T) {
+	dir, err := ioutil.TempDir("", "driverutil")
+	assert.NoError(t, err, "temp dir")
+	defer os.RemoveAll(dir)
+
+	var (
+		path    = filepath.Join(dir, "app.log")
+		rotated = filepath.Join(dir, "app.log.1")
+	)
+	sink, err := Open(l.Out(path))
+	assert.NoError(t, err, "open file")
+	sink.Write(l.INFO, []byte("entry1\n"))
+	assert.NoError(t, os.Rename(path, rotated), "rotate file")
+	assert.NoError(t, l.Reopen(), "reopen files")
+	sink.Write(l.INFO, []byte("entry2\n"))
+	assert.NoError(t, sink.Close(), "close sink")
+
+	for path, expected := range map[string]string{rotated: "entry1\n", path: "entry2\n"} {
+		data, err := ioutil.ReadFile(path)
+		assert.NoError(t, err, "file entries %s", path)
+		assert.Equal(t, expected, string(data), "file entries %s", path)
+	}
+}
+
+// testSyncWriter fails the Sync of the written entries
+type testSyncWriter struct {
+	bytes.Buffer
+	err error
+}
+
+func (writer *testSyncWriter) Sync() error {
+	return writer.err
+}
+
+func TestSinkClose(t *testing.T) {
+	var (
+		errSync = errors.New("err_sync")
+		sink    = NewSink(&testSyncWriter{err: errSync})
+	)
+	assert.True(t, errors.Is(sink.Sync(), errSync), "sync error")
+	assert.True(t, errors.Is(sink.Close(), errSync), "close error")
+	assert.NoError(t, sink.Close(), "close closed sink")
+	assert.NoError(t, sink.Sync(), "sync closed sink")
+}
+
+// testFailingWriter fails every write
+type testFailingWriter struct{}
+
+func (testFailingWriter) Write([]byte) (int, error) {
+	return 0, errors.New("disk full")
+}
+
+func TestSinkWriteError(t *testing.T) {
+	var reported []error
+	l.SetErrorHandler(func(err error) { reported = append(reported, err) })
+	defer l.SetErrorHandler(nil)
+	stderr, err := ioutil.TempFile("", "stderr")
+	assert.NoError(t, err, "stderr file")
+	defer os.Remove(stderr.Name())
+
+	defaultStderr := os.Stderr
+	os.Stderr = stderr
+	before := l.Stats()[l.INFO]
+	NewSink(testFailingWriter{}).Write(l.INFO, []byte("infolog\n"))
+	after := l.Stats()[l.INFO]
+	os.Stderr = defaultStderr
+	assert.NoError(t, stderr.Close(), "close stderr file")
+
+	assert.Equal(t, before.Failed+1, after.Failed, "failed entries")
+	assert.Equal(t, before.Written, after.Written, "written entries")
+	assert.Len(t, reported, 1, "reported errors")
+	data, err := ioutil.ReadFile(stderr.Name())
+	assert.NoError(t, err, "stderr entry")
+	assert.Contains(t, string(data), "infolog", "stderr entry")
+}
+
+// testChunkedWriter writes every byte on its own call, so entries written concurrently interleave without a lock
+type testChunkedWriter struct {
+	bytes.Buffer
+}
+
+func (writer *testChunkedWriter) Write(entry []byte) (int, error) {
+	for index := range entry {
+		writer.Buffer.WriteByte(entry[index])
+	}
+	return len(entry), nil
+}
+
+func TestSinkConcurrency(t *testing.T) {
+	var (
+		writer = new(testChunkedWriter)
+		sink   = NewSink(writer)
+		group  sync.WaitGroup
+	)
+	for index := 0; index < 50; index++ {
+		group.Add(1)
+		go func(index int) {
+			defer group.Done()
+			sink.Write(l.INFO, []byte(fmt.Sprintf("%d %s\n", index, strings.Repeat("v", index+1))))
+		}(index)
+	}
+	group.Wait()
+
+	entries := strings.Split(strings.TrimSpace(writer.String()), "\n")
+	assert.Len(t, entries, 50, "entries")
+	for _, entry := range entries {
+		var (
+			index int
+			value string
+		)
+		_, err := fmt.Sscanf(entry, "%d %s", &index, &value)
+		assert.NoError(t, err, "scan entry %s", entry)
+		assert.Equal(t, strings.Repeat("v", index+1), value, "value of entry %s", entry)
+	}
+}
@@ -0,0 +1,62 @@
+package driverutil
+
+import (
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/rjansen/l"
+)
+
+// ShortCaller formats the caller as the last directory, file and line like zapcore.ShortCallerEncoder
+func ShortCaller(frame runtime.Frame) string {
+	file := frame.File
+	if index := strings.LastIndexByte(file, '/'); index >= 0 {
+		if index = strings.LastIndexByte(file[:index], '/'); index >= 0 {
+			file = file[index+1:]
+		}
+	}
+	return file + ":" + strconv.Itoa(frame.Line)
+}
+
+// Stacktrace formats the goroutine stack like zap starting at the caller,
+// or after the frames of the l package and of the driver package when the caller is unknown
+func Stacktrace(caller l.Caller, driverPackage string) string {
+	pcs := make([]uintptr, 64)
+	pcs = pcs[:runtime.Callers(2, pcs)]
+
+	var (
+		builder  strings.Builder
+		frames   = runtime.CallersFrames(pcs)
+		callerPC = caller.Frame().PC
+		started  = false
+	)
+	for frame, more := frames.Next(); ; frame, more = frames.Next() {
+		if !started {
+			if callerPC != 0 {
+				started = frame.PC == callerPC
+			} else {
+				started = !isInternalFrame(frame.Function, driverPackage)
+			}
+		}
+		if started {
+			if builder.Len() > 0 {
+				builder.WriteByte('\n')
+			}
+			builder.WriteString(frame.Function)
+			builder.WriteString("\n\t")
+			builder.WriteString(frame.File)
+			builder.WriteByte(':')
+			builder.WriteString(strconv.Itoa(frame.Line))
+		}
+		if !more {
+			break
+		}
+	}
+	return builder.String()
+}
+
+func isInternalFrame(function string, driverPackage string) bool {
+	return strings.HasPrefix(function, "github.com/rjansen/l.") ||
+		strings.HasPrefix(function, driverPackage+".")
+}
@@ -0,0 +1,54 @@
+package driverutil
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/rjansen/l"
+	"github.com/stretchr/testify/assert"
+)
+
+type testShortCaller struct {
+	name     string
+	file     string
+	expected string
+}
+
+func TestShortCaller(test *testing.T) {
+	scenarios := []testShortCaller{
+		{
+			name:     "Keeps the last directory and file",
+			file:     "/go/src/github.com/rjansen/l/jsondriver/driver.go",
+			expected: "jsondriver/driver.go:10",
+		},
+		{
+			name:     "Keeps a file without directory",
+			file:     "driver.go",
+			expected: "driver.go:10",
+		},
+	}
+
+	for index, scenario := range scenarios {
+		test.Run(
+			fmt.Sprintf("[%d]-%s", index, scenario.name),
+			func(t *testing.T) {
+				assert.Equal(t, scenario.expected, ShortCaller(runtime.Frame{File: scenario.file, Line: 10}), "short caller")
+			},
+		)
+	}
+}
+
+// testStacktrace returns the stacktrace starting at its call site, like a driver writing for a Logger
+func testStacktrace() string {
+	return Stacktrace(l.CallerAt(1), "github.com/rjansen/l/internal/driverutil")
+}
+
+func TestStacktrace(t *testing.T) {
+	stack := testStacktrace()
+	assert.True(t, strings.HasPrefix(stack, "github.com/rjansen/l/internal/driverutil.TestStacktrace\n\t"), "stack %q", stack)
+
+	stack = Stacktrace(l.Caller{}, "github.com/rjansen/l/internal/driverutil")
+	assert.True(t, strings.HasPrefix(stack, "testing.tRunner\n\t"), "stack without caller %q", stack)
+}
@@ -0,0 +1,255 @@
+package jsondriver
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rjansen/l"
+	"github.com/rjansen/l/zapdriver"
+	"github.com/stretchr/testify/assert"
+)
+
+var testTime = time.Date(2019, 10, 1, 12, 30, 15, 123000000, time.UTC)
+
+func testClock() time.Time {
+	return testTime
+}
+
+// jsonKeys returns the top level keys of a json object in the encoded order
+func jsonKeys(t *testing.T, entry []byte) []string {
+	var (
+		decoder = json.NewDecoder(bytes.NewReader(entry))
+		keys    []string
+		depth   int
+	)
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			break
+		}
+		switch token := token.(type) {
+		case json.Delim:
+			if token == '{' || token == '[' {
+				depth++
+			} else {
+				depth--
+			}
+		case string:
+			if depth == 1 {
+				keys = append(keys, token)
+				var ignored json.RawMessage
+				assert.NoError(t, decoder.Decode(&ignored), "json value")
+			}
+		}
+	}
+	return keys
+}
+
+type testDriver struct {
+	name     string
+	options  []Option
+	log      func(l.Logger)
+	expected []string
+}
+
+func TestDriver(test *testing.T) {
+	scenarios := []testDriver{
+		{
+			name: "Writes an entry",
+			log: func(log l.Logger) {
+				log.Info(context.Background(), "infolog", l.String("key", "value"))
+			},
+			expected: []string{
+				`{"level":"info","time":"2019-10-01T12:30:15.123Z","message":"infolog","key":"value"}`,
+			},
+		},
+		{
+			name: "Writes named child entries with values",
+			log: func(log l.Logger) {
+				api := log.Named("api").With(l.String("component", "api"))
+				api.Named("db").With(l.Int("pool", 1)).Debug(context.Background(), "debuglog", l.Bool("ok", true))
+				api.Warn(context.Background(), "warnlog")
+			},
+			expected: []string{
+				`{"level":"debug","time":"2019-10-01T12:30:15.123Z","logger":"api.db","message":"debuglog","component":"api","pool":1,"ok":true}`,
+				`{"level":"warn","time":"2019-10-01T12:30:15.123Z","logger":"api","message":"warnlog","component":"api"}`,
+			},
+		},
+		{
+			name: "Writes namespaces opened on With",
+			log: func(log l.Logger) {
+				log.With(l.String("id", "request1"), l.Namespace("http")).
+					With(l.String("method", "GET")).
+					Trace(context.Background(), "tracelog", l.Int("status", 200))
+			},
+			expected: []string{
+				`{"level":"trace","time":"2019-10-01T12:30:15.123Z","message":"tracelog","id":"request1","http":{"method":"GET","status":200}}`,
+			},
+		},
+		{
+			name: "Resolves the lazy values of With on every write",
+			log: func(log l.Logger) {
+				var calls int
+				child := log.With(l.String("id", "request1"), l.Lazy("calls", func() interface{} { calls++; return calls }), l.String("method", "GET"))
+				child.Info(context.Background(), "infolog")
+				child.Info(context.Background(), "infolog", l.Int("status", 200))
+			},
+			expected: []string{
+				`{"level":"info","time":"2019-10-01T12:30:15.123Z","message":"infolog","id":"request1","calls":1,"method":"GET"}`,
+				`{"level":"info","time":"2019-10-01T12:30:15.123Z","message":"infolog","id":"request1","calls":2,"method":"GET","status":200}`,
+			},
+		},
+		{
+			name:    "Does not write entries below the driver level",
+			options: []Option{WithLevel(l.INFO)},
+			log: func(log l.Logger) {
+				log.Debug(context.Background(), "debuglog")
+				log.Info(context.Background(), "infolog")
+			},
+			expected: []string{
+				`{"level":"info","time":"2019-10-01T12:30:15.123Z","message":"infolog"}`,
+			},
+		},
+	}
+
+	for index, scenario := range scenarios {
+		test.Run(
+			fmt.Sprintf("[%d]-%s", index, scenario.name),
+			func(t *testing.T) {
+				var (
+					buffer = new(bytes.Buffer)
+					driver = New(buffer, append([]Option{WithClock(testClock)}, scenario.options...)...)
+				)
+				scenario.log(l.New(driver))
+				assert.NoError(t, driver.Close(context.Background()), "close driver")
+				assert.Equal(t, strings.Join(scenario.expected, "\n")+"\n", buffer.String(), "entries")
+			},
+		)
+	}
+}
+
+func TestDriverCaller(t *testing.T) {
+	var (
+		buffer = new(bytes.Buffer)
+		log    = l.New(New(buffer, WithClock(testClock), WithCaller(true)))
+	)
+	_, _, line, _ := runtime.Caller(0)
+	log.Info(context.Background(), "infolog")
+	assert.Equal(t,
+		fmt.Sprintf(`{"level":"info","time":"2019-10-01T12:30:15.123Z","caller":"jsondriver/driver_test.go:%d","message":"infolog"}`+"\n", line+1),
+		buffer.String(),
+		"entry",
+	)
+}
+
+func TestDriverStacktrace(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	log := l.New(New(buffer, WithClock(testClock), WithStacktraceLevel(l.WARN)))
+	log.Warn(context.Background(), "warnlog", l.String("key", "value"))
+
+	var entry map[string]interface{}
+	assert.NoError(t, json.Unmarshal(buffer.Bytes(), &entry), "json entry")
+	stack, _ := entry["stack"].(string)
+	assert.True(t, strings.HasPrefix(stack, "github.com/rjansen/l/jsondriver.TestDriverStacktrace\n\t"), "stack %q", stack)
+	assert.Equal(t, []string{"level", "time", "message", "key", "stack"}, jsonKeys(t, buffer.Bytes()), "keys")
+}
+
+func TestDriverZapLayout(t *testing.T) {
+	dir, err := ioutil.TempDir("", "jsondriver")
+	assert.NoError(t, err, "temp dir")
+	defer os.RemoveAll(dir)
+
+	var (
+		zapPath  = filepath.Join(dir, "zap.log")
+		jsonPath = filepath.Join(dir, "json.log")
+		values   = []l.Value{
+			l.String("string", "value"),
+			l.Int("int", 999),
+			l.Float64("float", 999.99),
+			l.Bool("bool", true),
+			l.Time("time", testTime),
+			l.Duration("duration", time.Second),
+			l.NamedErr("err", errors.New("errorvalue")),
+			l.Bytes("bytes", []byte("bytes1")),
+			l.Strings("strings", []string{"a", "b"}),
+			l.Group("group", l.String("nested", "value")),
+			l.NewValue("map", map[string]int{"b": 2, "a": 1}),
+		}
+		zapLogger, zapErr   = zapdriver.NewLogger(l.TRACE, l.Out(zapPath))
+		jsonDriver, jsonErr = Open(l.Out("file://"+jsonPath), WithClock(testClock))
+	)
+	assert.NoError(t, zapErr, "zap logger")
+	assert.NoError(t, jsonErr, "json driver")
+
+	for _, driver := range []l.Driver{zapdriver.New(zapLogger), jsonDriver} {
+		log := l.New(driver).Named("api")
+		log.Info(context.Background(), "infolog", values...)
+		log.Error(context.Background(), "errorlog", values...)
+		assert.NoError(t, driver.Close(context.Background()), "close driver")
+	}
+
+	zapData, err := ioutil.ReadFile(zapPath)
+	assert.NoError(t, err, "zap entries")
+	jsonData, err := ioutil.ReadFile(jsonPath)
+	assert.NoError(t, err, "json entries")
+
+	var (
+		zapEntries  = bytes.Split(bytes.TrimSpace(zapData), []byte("\n"))
+		jsonEntries = bytes.Split(bytes.TrimSpace(jsonData), []byte("\n"))
+	)
+	assert.Len(t, jsonEntries, len(zapEntries), "entries")
+	for index := range zapEntries {
+		assert.Equal(t, jsonKeys(t, zapEntries[index]), jsonKeys(t, jsonEntries[index]), "keys of entry %d", index)
+
+		var zapEntry, jsonEntry map[string]interface{}
+		assert.NoError(t, json.Unmarshal(zapEntries[index], &zapEntry), "zap entry")
+		assert.NoError(t, json.Unmarshal(jsonEntries[index], &jsonEntry), "json entry")
+		for _, entry := range []map[string]interface{}{zapEntry, jsonEntry} {
+			delete(entry, "time")
+			delete(entry, "stack")
+		}
+		assert.Equal(t, zapEntry, jsonEntry, "entry %d", index)
+	}
+}
+
+func TestOpen(t *testing.T) {
+	for _, out := range []l.Out{l.STDOUT, l.STDERR} {
+		driver, err := Open(out)
+		assert.NoError(t, err, "open %s", out)
+		assert.NotNil(t, driver, "driver %s", out)
+		assert.NoError(t, driver.Close(context.Background()), "close %s", out)
+	}
+	driver, err := Open(l.Out("/invalid/path/app.log"))
+	assert.Error(t, err, "open error")
+	assert.Nil(t, driver, "driver instance")
+}
+
+func BenchmarkDriver(b *testing.B) {
+	var (
+		log = l.New(New(ioutil.Discard))
+		ctx = context.Background()
+		now = time.Now()
+	)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for index := 0; index < b.N; index++ {
+		log.Info(ctx, "benchmark",
+			l.String("string", "value"),
+			l.Int("int", index),
+			l.Float64("float", 999.99),
+			l.Bool("bool", true),
+			l.Time("time", now),
+			l.Duration("duration", time.Second),
+		)
+	}
+}
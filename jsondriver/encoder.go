@@ -0,0 +1,265 @@
+package jsondriver
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+	"unicode/utf8"
+
+	"github.com/rjansen/l"
+)
+
+// TimeLayout is the layout of times written by the driver, the same ISO8601 layout of zapdriver.NewLogger
+const TimeLayout = "2006-01-02T15:04:05.000Z0700"
+
+const hex = "0123456789abcdef"
+
+// encoder appends json fields to a buffer, it tracks the namespaces opened by l.Namespace values
+type encoder struct {
+	buffer     []byte
+	namespaces int
+}
+
+func (enc *encoder) appendKey(key string) {
+	last := len(enc.buffer) - 1
+	if last >= 0 && enc.buffer[last] != '{' {
+		enc.buffer = append(enc.buffer, ',')
+	}
+	enc.buffer = appendString(enc.buffer, key)
+	enc.buffer = append(enc.buffer, ':')
+}
+
+func (enc *encoder) closeNamespaces() {
+	for ; enc.namespaces > 0; enc.namespaces-- {
+		enc.buffer = append(enc.buffer, '}')
+	}
+}
+
+func (enc *encoder) appendValues(values []l.Value) {
+	for _, value := range values {
+		enc.appendValue(value.Resolve())
+	}
+}
+
+func (enc *encoder) appendValue(value l.Value) {
+	switch value.Kind() {
+	case l.StringKind:
+		enc.appendKey(value.Name())
+		enc.buffer = appendString(enc.buffer, value.AsString())
+	case l.Int64Kind:
+		enc.appendKey(value.Name())
+		enc.buffer = strconv.AppendInt(enc.buffer, value.AsInt64(), 10)
+	case l.Uint64Kind:
+		enc.appendKey(value.Name())
+		enc.buffer = strconv.AppendUint(enc.buffer, value.AsUint64(), 10)
+	case l.Float64Kind:
+		enc.appendKey(value.Name())
+		enc.buffer = appendFloat(enc.buffer, value.AsFloat64())
+	case l.BoolKind:
+		enc.appendKey(value.Name())
+		enc.buffer = strconv.AppendBool(enc.buffer, value.AsBool())
+	case l.TimeKind:
+		enc.appendKey(value.Name())
+		enc.buffer = appendTime(enc.buffer, value.AsTime())
+	case l.DurationKind:
+		enc.appendKey(value.Name())
+		enc.buffer = appendString(enc.buffer, value.AsDuration().String())
+	case l.ErrorKind:
+		if err := value.AsError(); err != nil {
+			enc.appendKey(value.Name())
+			enc.buffer = appendString(enc.buffer, err.Error())
+		}
+	case l.BytesKind:
+		enc.appendKey(value.Name())
+		enc.buffer = appendBytes(enc.buffer, value.AsBytes())
+	case l.StringsKind:
+		enc.appendKey(value.Name())
+		enc.buffer = appendStrings(enc.buffer, value.AsStrings())
+	case l.GroupKind:
+		enc.appendGroup(value.Name(), value.AsGroup())
+	case l.NamespaceKind:
+		enc.appendKey(value.Name())
+		enc.buffer = append(enc.buffer, '{')
+		enc.namespaces++
+	default:
+		enc.appendAny(value.Name(), value.Any())
+	}
+}
+
+func (enc *encoder) appendGroup(name string, values []l.Value) {
+	if len(values) == 0 {
+		return
+	}
+	if name == "" {
+		enc.appendValues(values)
+		return
+	}
+	enc.appendKey(name)
+	group := encoder{buffer: append(enc.buffer, '{')}
+	group.appendValues(values)
+	group.closeNamespaces()
+	enc.buffer = append(group.buffer, '}')
+}
+
+// appendAny encodes the common types without reflection and falls back to encoding/json for any other type
+func (enc *encoder) appendAny(name string, value interface{}) {
+	switch value := value.(type) {
+	case nil:
+		enc.appendKey(name)
+		enc.buffer = append(enc.buffer, "null"...)
+	case string:
+		enc.appendValue(l.String(name, value))
+	case int:
+		enc.appendValue(l.Int64(name, int64(value)))
+	case int8:
+		enc.appendValue(l.Int64(name, int64(value)))
+	case int16:
+		enc.appendValue(l.Int64(name, int64(value)))
+	case int32:
+		enc.appendValue(l.Int64(name, int64(value)))
+	case int64:
+		enc.appendValue(l.Int64(name, value))
+	case uint:
+		enc.appendValue(l.Uint64(name, uint64(value)))
+	case uint8:
+		enc.appendValue(l.Uint64(name, uint64(value)))
+	case uint16:
+		enc.appendValue(l.Uint64(name, uint64(value)))
+	case uint32:
+		enc.appendValue(l.Uint64(name, uint64(value)))
+	case uint64:
+		enc.appendValue(l.Uint64(name, value))
+	case float32:
+		enc.appendKey(name)
+		enc.buffer = appendFloat32(enc.buffer, value)
+	case float64:
+		enc.appendValue(l.Float64(name, value))
+	case bool:
+		enc.appendValue(l.Bool(name, value))
+	case time.Time:
+		enc.appendValue(l.Time(name, value))
+	case time.Duration:
+		enc.appendValue(l.Duration(name, value))
+	case error:
+		enc.appendValue(l.NamedErr(name, value))
+	case []byte:
+		enc.appendValue(l.Bytes(name, value))
+	case []string:
+		enc.appendValue(l.Strings(name, value))
+	case fmt.Stringer:
+		enc.appendValue(l.String(name, value.String()))
+	default:
+		enc.appendReflected(name, value)
+	}
+}
+
+func (enc *encoder) appendReflected(name string, value interface{}) {
+	var (
+		reflected bytes.Buffer
+		jsonEnc   = json.NewEncoder(&reflected)
+	)
+	jsonEnc.SetEscapeHTML(false)
+	if err := jsonEnc.Encode(value); err != nil {
+		enc.appendKey(name + "Error")
+		enc.buffer = appendString(enc.buffer, err.Error())
+		return
+	}
+	enc.appendKey(name)
+	enc.buffer = append(enc.buffer, bytes.TrimRight(reflected.Bytes(), "\n")...)
+}
+
+func appendFloat(buffer []byte, value float64) []byte {
+	switch {
+	case math.IsNaN(value):
+		return append(buffer, `"NaN"`...)
+	case math.IsInf(value, 1):
+		return append(buffer, `"+Inf"`...)
+	case math.IsInf(value, -1):
+		return append(buffer, `"-Inf"`...)
+	default:
+		return strconv.AppendFloat(buffer, value, 'f', -1, 64)
+	}
+}
+
+func appendFloat32(buffer []byte, value float32) []byte {
+	if math.IsNaN(float64(value)) || math.IsInf(float64(value), 0) {
+		return appendFloat(buffer, float64(value))
+	}
+	return strconv.AppendFloat(buffer, float64(value), 'f', -1, 32)
+}
+
+func appendTime(buffer []byte, value time.Time) []byte {
+	buffer = append(buffer, '"')
+	buffer = value.AppendFormat(buffer, TimeLayout)
+	return append(buffer, '"')
+}
+
+func appendBytes(buffer []byte, value []byte) []byte {
+	buffer = append(buffer, '"')
+	start := len(buffer)
+	size := base64.StdEncoding.EncodedLen(len(value))
+	if cap(buffer)-start < size+1 {
+		grown := make([]byte, start, start+size+1)
+		copy(grown, buffer)
+		buffer = grown
+	}
+	buffer = buffer[:start+size]
+	base64.StdEncoding.Encode(buffer[start:], value)
+	return append(buffer, '"')
+}
+
+func appendStrings(buffer []byte, values []string) []byte {
+	buffer = append(buffer, '[')
+	for index, value := range values {
+		if index > 0 {
+			buffer = append(buffer, ',')
+		}
+		buffer = appendString(buffer, value)
+	}
+	return append(buffer, ']')
+}
+
+// appendString appends a quoted json string, escaping control characters and replacing invalid utf8 like zap does
+func appendString(buffer []byte, value string) []byte {
+	buffer = append(buffer, '"')
+	start := 0
+	for index := 0; index < len(value); {
+		if char := value[index]; char < utf8.RuneSelf {
+			if char >= 0x20 && char != '\\' && char != '"' {
+				index++
+				continue
+			}
+			buffer = append(buffer, value[start:index]...)
+			switch char {
+			case '\\', '"':
+				buffer = append(buffer, '\\', char)
+			case '\n':
+				buffer = append(buffer, '\\', 'n')
+			case '\r':
+				buffer = append(buffer, '\\', 'r')
+			case '\t':
+				buffer = append(buffer, '\\', 't')
+			default:
+				buffer = append(buffer, '\\', 'u', '0', '0', hex[char>>4], hex[char&0xF])
+			}
+			index++
+			start = index
+			continue
+		}
+		char, size := utf8.DecodeRuneInString(value[index:])
+		if char == utf8.RuneError && size == 1 {
+			buffer = append(buffer, value[start:index]...)
+			buffer = append(buffer, `\ufffd`...)
+			index += size
+			start = index
+			continue
+		}
+		index += size
+	}
+	buffer = append(buffer, value[start:]...)
+	return append(buffer, '"')
+}
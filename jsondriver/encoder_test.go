@@ -0,0 +1,158 @@
+package jsondriver
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/rjansen/l"
+	"github.com/stretchr/testify/assert"
+)
+
+type testStringer struct{}
+
+func (testStringer) String() string {
+	return "stringer"
+}
+
+type testEncoder struct {
+	name     string
+	values   []l.Value
+	expected string
+}
+
+func TestEncoder(test *testing.T) {
+	var (
+		timeValue = time.Date(2019, 10, 1, 12, 30, 15, 123456789, time.UTC)
+		channel   = make(chan int)
+	)
+	scenarios := []testEncoder{
+		{
+			name: "Encodes typed values",
+			values: []l.Value{
+				l.String("string", "value"),
+				l.Int("int", -999),
+				l.Uint64("uint", 999),
+				l.Float64("float", 999.99),
+				l.Bool("bool", true),
+				l.Time("time", timeValue),
+				l.Duration("duration", 1500*time.Millisecond),
+				l.Err(errors.New("errorvalue")),
+				l.Bytes("bytes", []byte("bytes1")),
+				l.Strings("strings", []string{"a", "b"}),
+			},
+			expected: `{"string":"value","int":-999,"uint":999,"float":999.99,"bool":true,` +
+				`"time":"2019-10-01T12:30:15.123Z","duration":"1.5s","error":"errorvalue",` +
+				`"bytes":"Ynl0ZXMx","strings":["a","b"]}`,
+		},
+		{
+			name: "Encodes any values without reflection",
+			values: []l.Value{
+				l.NewValue("nil", nil),
+				l.NewValue("string", "value"),
+				l.NewValue("int", 999),
+				l.NewValue("int32", int32(-999)),
+				l.NewValue("uint8", uint8(9)),
+				l.NewValue("float32", float32(999.99)),
+				l.NewValue("float64", 999.99),
+				l.NewValue("bool", false),
+				l.NewValue("time", timeValue),
+				l.NewValue("duration", time.Second),
+				l.NewValue("error", errors.New("errorvalue")),
+				l.NewValue("bytes", []byte("bytes1")),
+				l.NewValue("strings", []string{"a"}),
+				l.NewValue("stringer", testStringer{}),
+			},
+			expected: `{"nil":null,"string":"value","int":999,"int32":-999,"uint8":9,"float32":999.99,` +
+				`"float64":999.99,"bool":false,"time":"2019-10-01T12:30:15.123Z","duration":"1s",` +
+				`"error":"errorvalue","bytes":"Ynl0ZXMx","strings":["a"],"stringer":"stringer"}`,
+		},
+		{
+			name: "Encodes reflected values",
+			values: []l.Value{
+				l.NewValue("map", map[string]interface{}{"b": 2, "a": "<html>"}),
+				l.NewValue("struct", struct {
+					Name string `json:"name"`
+				}{Name: "name1"}),
+				l.NewValue("channel", channel),
+			},
+			expected: `{"map":{"a":"<html>","b":2},"struct":{"name":"name1"},` +
+				`"channelError":"json: unsupported type: chan int"}`,
+		},
+		{
+			name: "Encodes special floats as strings",
+			values: []l.Value{
+				l.Float64("nan", math.NaN()),
+				l.Float64("inf", math.Inf(1)),
+				l.Float64("-inf", math.Inf(-1)),
+				l.NewValue("float32nan", float32(math.NaN())),
+			},
+			expected: `{"nan":"NaN","inf":"+Inf","-inf":"-Inf","float32nan":"NaN"}`,
+		},
+		{
+			name: "Escapes strings",
+			values: []l.Value{
+				l.String("quote\"key", "line1\nline2\t\"quoted\" \\ \r\x01 é <tag>"),
+				l.String("invalid", "a\xffb"),
+			},
+			expected: `{"quote\"key":"line1\nline2\t\"quoted\" \\ \r\u0001 é <tag>","invalid":"a\ufffdb"}`,
+		},
+		{
+			name: "Skips nil errors and empty groups",
+			values: []l.Value{
+				l.Err(nil),
+				l.Group("empty"),
+				l.String("key", "value"),
+			},
+			expected: `{"key":"value"}`,
+		},
+		{
+			name: "Encodes groups and namespaces",
+			values: []l.Value{
+				l.Group("http", l.String("method", "GET"), l.Group("response", l.Int("status", 200))),
+				l.Group("", l.String("inline", "value")),
+				l.Namespace("request"),
+				l.String("id", "request1"),
+				l.Namespace("user"),
+				l.String("id", "user1"),
+			},
+			expected: `{"http":{"method":"GET","response":{"status":200}},"inline":"value",` +
+				`"request":{"id":"request1","user":{"id":"user1"}}}`,
+		},
+		{
+			name: "Encodes lazy values",
+			values: []l.Value{
+				l.Lazy("lazy", func() interface{} { return 999 }),
+			},
+			expected: `{"lazy":999}`,
+		},
+	}
+
+	for index, scenario := range scenarios {
+		test.Run(
+			fmt.Sprintf("[%d]-%s", index, scenario.name),
+			func(t *testing.T) {
+				enc := encoder{buffer: []byte{'{'}}
+				enc.appendValues(scenario.values)
+				enc.closeNamespaces()
+				enc.buffer = append(enc.buffer, '}')
+				assert.Equal(t, scenario.expected, string(enc.buffer), "encoded values")
+				assert.True(t, json.Valid(enc.buffer), "valid json")
+			},
+		)
+	}
+}
+
+func TestAppendBytes(t *testing.T) {
+	for size := 0; size < 64; size++ {
+		value := make([]byte, size)
+		for index := range value {
+			value[index] = byte(index)
+		}
+		expected, _ := json.Marshal(value)
+		assert.Equal(t, string(expected), string(appendBytes(make([]byte, 0, size%7), value)), "bytes of size %d", size)
+	}
+}
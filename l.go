@@ -2,7 +2,11 @@ package l
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"os"
+	"strings"
 )
 
 const (
@@ -11,12 +15,20 @@ const (
 	//STDERR redirects any message to stderr
 	STDERR Out = "stderr"
 
+	//FATAL is the fatal level logger, the process exits after the message is written
+	FATAL Level = "fatal"
+	//PANIC is the panic level logger, the logger panics after the message is written
+	PANIC Level = "panic"
 	//ERROR is the error level logger
 	ERROR Level = "error"
+	//WARN is the warn level logger
+	WARN Level = "warn"
 	//INFO is the info level logger
 	INFO Level = "info"
 	//DEBUG is the debug level logger
 	DEBUG Level = "debug"
+	//TRACE is the trace level logger
+	TRACE Level = "trace"
 )
 
 //Out is the type for logger writer config
@@ -26,19 +38,64 @@ func (o Out) String() string {
 	return string(o)
 }
 
+// ParseOut parses an output, empty values are STDOUT and any value that is not a standard stream is a file path or a file:// URL.
+// Several destinations are separated by commas and each one may set its minimum level and encoding,
+// like stdout?encoding=console,/var/log/app.log?level=info
+func ParseOut(value string) (Out, error) {
+	if value == "" {
+		return STDOUT, nil
+	}
+	destinations, err := Out(value).Destinations()
+	if err != nil {
+		return "", err
+	}
+	for index, destination := range destinations {
+		out, err := parseSingleOut(destination.Out.String())
+		if err != nil {
+			return "", err
+		}
+		if destination.Rotation.Enabled() && (out == STDOUT || out == STDERR) {
+			return "", fmt.Errorf("err_invalid_out{Out=%q, Message='rotation needs a file'}", destination.String())
+		}
+		destinations[index].Out = out
+	}
+	return NewOut(destinations...), nil
+}
+
 // Set is a utility method for flag system usage
 func (o *Out) Set(value string) error {
-	switch value {
-	case "stdout", "STDOUT", "":
-		*o = STDOUT
-	case "stderr", "STDERR":
-		*o = STDERR
-	default:
-		*o = Out(value)
+	out, err := ParseOut(value)
+	if err != nil {
+		return err
 	}
+	*o = out
 	return nil
 }
 
+// MarshalText implements encoding.TextMarshaler
+func (o Out) MarshalText() ([]byte, error) {
+	return []byte(o), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler
+func (o *Out) UnmarshalText(text []byte) error {
+	return o.Set(string(text))
+}
+
+// MarshalJSON implements json.Marshaler
+func (o Out) MarshalJSON() ([]byte, error) {
+	return json.Marshal(string(o))
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (o *Out) UnmarshalJSON(data []byte) error {
+	var value string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	return o.Set(value)
+}
+
 //Level is the threshold of the logger
 type Level string
 
@@ -47,32 +104,115 @@ func (l Level) String() string {
 	return string(l)
 }
 
+// ParseLevel parses a case insensitive level name, an empty value is DEBUG and unknown names are an error
+func ParseLevel(value string) (Level, error) {
+	switch level := Level(strings.ToLower(value)); level {
+	case "":
+		return DEBUG, nil
+	case TRACE, DEBUG, INFO, WARN, ERROR, PANIC, FATAL:
+		return level, nil
+	default:
+		return "", fmt.Errorf("err_invalid_level{Level=%q}", value)
+	}
+}
+
 // Set is a utility method for flag system usage
 func (l *Level) Set(value string) error {
-	switch value {
-	case "info", "INFO":
-		*l = INFO
-	case "error", "ERROR":
-		*l = ERROR
-	default:
-		*l = DEBUG
+	level, err := ParseLevel(value)
+	if err != nil {
+		return err
 	}
+	*l = level
 	return nil
 }
 
-type Value struct {
-	name  string
-	value interface{}
+// MarshalText implements encoding.TextMarshaler
+func (l Level) MarshalText() ([]byte, error) {
+	return []byte(l), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler
+func (l *Level) UnmarshalText(text []byte) error {
+	return l.Set(string(text))
+}
+
+// MarshalJSON implements json.Marshaler
+func (l Level) MarshalJSON() ([]byte, error) {
+	return json.Marshal(string(l))
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (l *Level) UnmarshalJSON(data []byte) error {
+	var value string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	return l.Set(value)
+}
+
+// severity returns the order of the level from TRACE to FATAL and false when the level is unknown
+func (l Level) severity() (int, bool) {
+	switch l {
+	case TRACE:
+		return 0, true
+	case DEBUG:
+		return 1, true
+	case INFO:
+		return 2, true
+	case WARN:
+		return 3, true
+	case ERROR:
+		return 4, true
+	case PANIC:
+		return 5, true
+	case FATAL:
+		return 6, true
+	default:
+		return -1, false
+	}
 }
 
-func NewValue(name string, value interface{}) Value {
-	return Value{name: name, value: value}
+// Enabled reports whether a message at this level passes the provided threshold, unknown levels are never enabled
+func (l Level) Enabled(threshold Level) bool {
+	levelSeverity, levelOK := l.severity()
+	thresholdSeverity, thresholdOK := threshold.severity()
+	return levelOK && thresholdOK && levelSeverity >= thresholdSeverity
+}
+
+// Compare returns -1, 0 or +1 when the level is lower, equal or higher than the other one, unknown levels are the lowest
+func (l Level) Compare(other Level) int {
+	levelSeverity, _ := l.severity()
+	otherSeverity, _ := other.severity()
+	switch {
+	case levelSeverity < otherSeverity:
+		return -1
+	case levelSeverity > otherSeverity:
+		return 1
+	default:
+		return 0
+	}
 }
 
 type Logger interface {
+	Trace(context.Context, string, ...Value)
 	Debug(context.Context, string, ...Value)
 	Info(context.Context, string, ...Value)
+	Warn(context.Context, string, ...Value)
 	Error(context.Context, string, ...Value)
+	// Panic writes the message and then panics with it
+	Panic(context.Context, string, ...Value)
+	// Fatal writes the message and then exits the process with status 1
+	Fatal(context.Context, string, ...Value)
+	// With returns a child Logger that writes the provided values on every log call
+	With(...Value) Logger
+	// Named returns a child Logger with the provided name appended to the current one using a dot separator
+	Named(string) Logger
+	// LevelController changes the threshold of the Logger and all of its children at runtime
+	LevelController
+	// Sync writes the buffered entries of the driver, the context bounds the wait
+	Sync(context.Context) error
+	// Close syncs and releases the outputs of the driver, it is shared by the Logger and all of its children
+	Close(context.Context) error
 }
 
 type LogWriter interface {
@@ -81,19 +221,102 @@ type LogWriter interface {
 
 type Driver interface {
 	Log(Level, string) LogWriter
-	Close()
+	With(...Value) Driver
+	Named(string) Driver
+	// Sync writes the buffered entries to the outputs
+	Sync(context.Context) error
+	// Close syncs and releases the outputs, the driver writes nothing after it
+	Close(context.Context) error
+}
+
+// LevelDriver is a Driver with its own threshold, like the zap driver of a logger built by zapdriver.NewLogger,
+// New and NewWithLevelRules start the root threshold at it and keep it at the lowest level of the Logger and its named children
+type LevelDriver interface {
+	Driver
+	// LevelController returns the threshold of the driver, nil when it has none
+	LevelController() LevelController
+}
+
+// driverLevels returns the LevelRegistry of a Logger over the driver: the threshold of a LevelDriver follows the
+// registry, any other driver gets a TRACE root which leaves the filtering to the driver
+func driverLevels(driver Driver) *LevelRegistry {
+	if leveled, ok := driver.(LevelDriver); ok {
+		if level := leveled.LevelController(); level != nil {
+			return newDriverLevelRegistry(level)
+		}
+	}
+	return NewLevelRegistry(nil)
+}
+
+// NameSeparator is the separator used to join hierarchical logger names
+const NameSeparator = "."
+
+// JoinName appends name to the parent logger name using NameSeparator
+func JoinName(parent, name string) string {
+	switch {
+	case name == "":
+		return parent
+	case parent == "":
+		return name
+	default:
+		return parent + NameSeparator + name
+	}
 }
 
 type logger struct {
+	name   string
+	level  LevelController
+	levels *LevelRegistry
 	driver Driver
 }
 
-func (log logger) log(_ context.Context, level Level, msg string, values ...Value) {
-	if writer := log.driver.Log(level, msg); writer != nil {
-		writer.Write(values...)
+// loggerCallerSkip is the CallerAt skip from logger.write to the user call site,
+// the Logger methods and the package level functions must call logger.log directly to keep it accurate
+const loggerCallerSkip = 3
+
+func (log logger) log(ctx context.Context, level Level, msg string, values ...Value) {
+	log.write(ctx, level, Caller{}, true, msg, values)
+}
+
+// LogCaller writes a log call made at the provided call site, it implements CallerLogger
+func (log logger) LogCaller(ctx context.Context, level Level, caller Caller, msg string, values ...Value) {
+	log.write(ctx, level, caller, false, msg, values)
+}
+
+func (log logger) write(ctx context.Context, level Level, caller Caller, capture bool, msg string, values []Value) {
+	if log.level.Enabled(level) {
+		if writer := log.driver.Log(level, msg); writer != nil {
+			switch writer := writer.(type) {
+			case ContextWriter:
+				if capture {
+					caller = CallerAt(loggerCallerSkip)
+				}
+				writer.WriteContext(ctx, caller, mergeContextValues(ctx, values)...)
+			case CallerWriter:
+				if capture {
+					caller = CallerAt(loggerCallerSkip)
+				}
+				writer.WriteCaller(caller, mergeContextValues(ctx, values)...)
+			default:
+				writer.Write(mergeContextValues(ctx, values)...)
+			}
+		}
+	}
+	switch level {
+	case PANIC:
+		panic(msg)
+	case FATAL:
+		exit(1)
 	}
 }
 
+// exit terminates the process after a FATAL message, it is replaced on tests
+var exit = os.Exit
+
+func (log logger) Trace(ctx context.Context, msg string, values ...Value) {
+	log.log(ctx, TRACE, msg, values...)
+}
+
 func (log logger) Debug(ctx context.Context, msg string, values ...Value) {
 	log.log(ctx, DEBUG, msg, values...)
 }
@@ -102,16 +325,110 @@ func (log logger) Info(ctx context.Context, msg string, values ...Value) {
 	log.log(ctx, INFO, msg, values...)
 }
 
+func (log logger) Warn(ctx context.Context, msg string, values ...Value) {
+	log.log(ctx, WARN, msg, values...)
+}
+
 func (log logger) Error(ctx context.Context, msg string, values ...Value) {
 	log.log(ctx, ERROR, msg, values...)
 }
 
+func (log logger) Panic(ctx context.Context, msg string, values ...Value) {
+	log.log(ctx, PANIC, msg, values...)
+}
+
+func (log logger) Fatal(ctx context.Context, msg string, values ...Value) {
+	log.log(ctx, FATAL, msg, values...)
+}
+
+func (log logger) With(values ...Value) Logger {
+	if len(values) == 0 {
+		return log
+	}
+	return logger{
+		name:   log.name,
+		level:  log.level,
+		levels: log.levels,
+		driver: log.driver.With(values...),
+	}
+}
+
+func (log logger) Named(name string) Logger {
+	if name == "" {
+		return log
+	}
+	childName := JoinName(log.name, name)
+	return logger{
+		name:   childName,
+		level:  log.levels.Controller(childName),
+		levels: log.levels,
+		driver: log.driver.Named(name),
+	}
+}
+
+func (log logger) Level() Level {
+	return log.level.Level()
+}
+
+func (log logger) SetLevel(level Level) error {
+	return log.level.SetLevel(level)
+}
+
+func (log logger) Enabled(level Level) bool {
+	return log.level.Enabled(level)
+}
+
+func (log logger) Sync(ctx context.Context) error {
+	return log.driver.Sync(ctx)
+}
+
+func (log logger) Close(ctx context.Context) error {
+	return log.driver.Close(ctx)
+}
+
+// Levels returns the LevelRegistry shared by the Logger and every Logger derived from it
+func (log logger) Levels() *LevelRegistry {
+	return log.levels
+}
+
+// New creates a Logger with the threshold of a LevelDriver, which then follows the Logger levels, or a TRACE threshold
+// which leaves the filtering to the driver until SetLevel is called
 func New(driver Driver) Logger {
+	return newLogger(driver, driverLevels(driver))
+}
+
+// NewWithLevel creates a Logger which filters every log call with the provided LevelController before reaching the driver.
+// Named children get their own threshold from a LevelRegistry rooted on the provided LevelController.
+func NewWithLevel(driver Driver, level LevelController) Logger {
+	return newLogger(driver, NewLevelRegistry(level))
+}
+
+func newLogger(driver Driver, levels *LevelRegistry) Logger {
 	return logger{
+		level:  levels.Controller(""),
+		levels: levels,
 		driver: driver,
 	}
 }
 
+// NewWithLevelRules creates a Logger whose root and named thresholds follow the rules,
+// the root is the threshold of a LevelDriver or TRACE when the rules do not set it
+func NewWithLevelRules(driver Driver, rules LevelRules) (Logger, error) {
+	log := newLogger(driver, driverLevels(driver))
+	if err := LevelsOf(log).SetRules(rules); err != nil {
+		return nil, err
+	}
+	return log, nil
+}
+
+// LevelsOf returns the LevelRegistry of a Logger created by this package, or nil for any other Logger implementation
+func LevelsOf(log Logger) *LevelRegistry {
+	if registrar, ok := log.(interface{ Levels() *LevelRegistry }); ok {
+		return registrar.Levels()
+	}
+	return nil
+}
+
 func SetLoggerDefault(logger Logger) error {
 	if logger == nil {
 		return errors.New("err_invalid_parameter{Message='Logger is blank'}")
@@ -122,16 +439,60 @@ func SetLoggerDefault(logger Logger) error {
 }
 
 // LoggerDefault is the back-end implementation used on the log package level functions
-var LoggerDefault = NewZapLoggerDefault()
+var LoggerDefault = NewLoggerDefault()
+
+func Trace(ctx context.Context, msg string, values ...Value) {
+	if log, ok := LoggerDefault.(logger); ok {
+		log.log(ctx, TRACE, msg, values...)
+		return
+	}
+	LoggerDefault.Trace(ctx, msg, values...)
+}
 
 func Debug(ctx context.Context, msg string, values ...Value) {
+	if log, ok := LoggerDefault.(logger); ok {
+		log.log(ctx, DEBUG, msg, values...)
+		return
+	}
 	LoggerDefault.Debug(ctx, msg, values...)
 }
 
 func Info(ctx context.Context, msg string, values ...Value) {
+	if log, ok := LoggerDefault.(logger); ok {
+		log.log(ctx, INFO, msg, values...)
+		return
+	}
 	LoggerDefault.Info(ctx, msg, values...)
 }
 
+func Warn(ctx context.Context, msg string, values ...Value) {
+	if log, ok := LoggerDefault.(logger); ok {
+		log.log(ctx, WARN, msg, values...)
+		return
+	}
+	LoggerDefault.Warn(ctx, msg, values...)
+}
+
 func Error(ctx context.Context, msg string, values ...Value) {
+	if log, ok := LoggerDefault.(logger); ok {
+		log.log(ctx, ERROR, msg, values...)
+		return
+	}
 	LoggerDefault.Error(ctx, msg, values...)
 }
+
+func Panic(ctx context.Context, msg string, values ...Value) {
+	if log, ok := LoggerDefault.(logger); ok {
+		log.log(ctx, PANIC, msg, values...)
+		return
+	}
+	LoggerDefault.Panic(ctx, msg, values...)
+}
+
+func Fatal(ctx context.Context, msg string, values ...Value) {
+	if log, ok := LoggerDefault.(logger); ok {
+		log.log(ctx, FATAL, msg, values...)
+		return
+	}
+	LoggerDefault.Fatal(ctx, msg, values...)
+}
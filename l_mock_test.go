@@ -1,6 +1,8 @@
 package l
 
 import (
+	"context"
+
 	"github.com/stretchr/testify/mock"
 )
 
@@ -25,8 +27,24 @@ func (mock *mockDriver) Log(level Level, msg string) LogWriter {
 	return result.(LogWriter)
 }
 
-func (mock *mockDriver) Close() {
-	mock.Called()
+func (mock *mockDriver) With(values ...Value) Driver {
+	args := mock.Called(values)
+	return args.Get(0).(Driver)
+}
+
+func (mock *mockDriver) Named(name string) Driver {
+	args := mock.Called(name)
+	return args.Get(0).(Driver)
+}
+
+func (mock *mockDriver) Sync(ctx context.Context) error {
+	args := mock.Called(ctx)
+	return args.Error(0)
+}
+
+func (mock *mockDriver) Close(ctx context.Context) error {
+	args := mock.Called(ctx)
+	return args.Error(0)
 }
 
 type mockLogWriter struct {
@@ -2,8 +2,10 @@ package l
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"os"
 	"testing"
 	"time"
 
@@ -123,6 +125,7 @@ type testOut struct {
 	name     string
 	output   string
 	expected Out
+	err      error
 }
 
 func TestOut(test *testing.T) {
@@ -157,6 +160,23 @@ func TestOut(test *testing.T) {
 			output:   "STDERR",
 			expected: STDERR,
 		},
+		{
+			name:     "Creates a file URL Out",
+			output:   "file:///var/log/app.log",
+			expected: Out("file:///var/log/app.log"),
+		},
+		{
+			name:     "Does not create a blank Out",
+			output:   "  ",
+			expected: "",
+			err:      errors.New("err_invalid_out{Out=\"  \"}"),
+		},
+		{
+			name:     "Does not create an unsupported scheme Out",
+			output:   "http://localhost/logs",
+			expected: "",
+			err:      errors.New("err_invalid_out{Out=\"http://localhost/logs\", Message='unsupported scheme'}"),
+		},
 	}
 
 	for index, scenario := range scenarios {
@@ -167,7 +187,7 @@ func TestOut(test *testing.T) {
 					out Out
 					err = out.Set(scenario.output)
 				)
-				assert.Nil(t, err, "Out.Set error")
+				assert.Equal(t, scenario.err, err, "Out.Set error")
 				assert.Exactly(t, scenario.expected, out, "out instance")
 			},
 		)
@@ -178,6 +198,7 @@ type testLevel struct {
 	name     string
 	level    string
 	expected Level
+	err      error
 }
 
 func TestLevel(test *testing.T) {
@@ -188,9 +209,21 @@ func TestLevel(test *testing.T) {
 			expected: DEBUG,
 		},
 		{
-			name:     "Creates an invalid Level",
+			name:     "Does not create an invalid Level",
 			level:    "invalid",
-			expected: DEBUG,
+			expected: "",
+			err:      errors.New("err_invalid_level{Level=\"invalid\"}"),
+		},
+		{
+			name:     "Does not create a misspelled Level",
+			level:    "eror",
+			expected: "",
+			err:      errors.New("err_invalid_level{Level=\"eror\"}"),
+		},
+		{
+			name:     "Creates a mixed case Warn Level",
+			level:    "Warn",
+			expected: WARN,
 		},
 		{
 			name:     "Creates a debug Level",
@@ -222,6 +255,26 @@ func TestLevel(test *testing.T) {
 			level:    "ERROR",
 			expected: ERROR,
 		},
+		{
+			name:     "Creates a trace Level",
+			level:    "trace",
+			expected: TRACE,
+		},
+		{
+			name:     "Creates a WARN Level",
+			level:    "WARN",
+			expected: WARN,
+		},
+		{
+			name:     "Creates a panic Level",
+			level:    "panic",
+			expected: PANIC,
+		},
+		{
+			name:     "Creates a FATAL Level",
+			level:    "FATAL",
+			expected: FATAL,
+		},
 	}
 
 	for index, scenario := range scenarios {
@@ -232,7 +285,7 @@ func TestLevel(test *testing.T) {
 					level Level
 					err   = level.Set(scenario.level)
 				)
-				assert.Nil(t, err, "Level.Set error")
+				assert.Equal(t, scenario.err, err, "Level.Set error")
 				assert.Exactly(t, scenario.expected, level, "level instance")
 			},
 		)
@@ -248,3 +301,333 @@ func TestLoggerDefault(t *testing.T) {
 	err = SetLoggerDefault(New(newMockDriver()))
 	assert.Nil(t, err)
 }
+
+type testLoggerChild struct {
+	name     string
+	names    []string
+	values   []Value
+	expected string
+}
+
+func TestLoggerChild(test *testing.T) {
+	scenarios := []testLoggerChild{
+		{
+			name:     "Creates a named child Logger",
+			names:    []string{"api"},
+			expected: "api",
+		},
+		{
+			name:     "Creates a hierarchical named child Logger",
+			names:    []string{"api", "db", "pool"},
+			expected: "api.db.pool",
+		},
+		{
+			name:     "Creates a child Logger with values",
+			values:   []Value{NewValue("component", "api")},
+			expected: "",
+		},
+		{
+			name:     "Creates a named child Logger with values",
+			names:    []string{"api", "", "db"},
+			values:   []Value{NewValue("component", "db")},
+			expected: "api.db",
+		},
+	}
+
+	for index, scenario := range scenarios {
+		test.Run(
+			fmt.Sprintf("[%d]-%s", index, scenario.name),
+			func(t *testing.T) {
+				driver := newMockDriver()
+				driver.On("Named", mock.AnythingOfType("string")).Return(driver)
+				driver.On("With", mock.AnythingOfType("[]l.Value")).Return(driver)
+
+				var log Logger = New(driver)
+				for _, name := range scenario.names {
+					log = log.Named(name)
+				}
+				log = log.With(scenario.values...)
+				assert.Equal(t, scenario.expected, log.(logger).name, "logger name")
+
+				for _, name := range scenario.names {
+					if name != "" {
+						driver.AssertCalled(t, "Named", name)
+					}
+				}
+				if len(scenario.values) > 0 {
+					driver.AssertCalled(t, "With", scenario.values)
+				} else {
+					driver.AssertNotCalled(t, "With", mock.Anything)
+				}
+			},
+		)
+	}
+}
+
+type testLevelEnabled struct {
+	name      string
+	level     Level
+	threshold Level
+	enabled   bool
+	compare   int
+}
+
+func TestLevelEnabled(test *testing.T) {
+	scenarios := []testLevelEnabled{
+		{
+			name:      "Enables the same level",
+			level:     INFO,
+			threshold: INFO,
+			enabled:   true,
+			compare:   0,
+		},
+		{
+			name:      "Enables a level above the threshold",
+			level:     WARN,
+			threshold: INFO,
+			enabled:   true,
+			compare:   1,
+		},
+		{
+			name:      "Disables a level below the threshold",
+			level:     TRACE,
+			threshold: DEBUG,
+			enabled:   false,
+			compare:   -1,
+		},
+		{
+			name:      "Enables fatal above panic",
+			level:     FATAL,
+			threshold: PANIC,
+			enabled:   true,
+			compare:   1,
+		},
+		{
+			name:      "Disables error below panic",
+			level:     ERROR,
+			threshold: PANIC,
+			enabled:   false,
+			compare:   -1,
+		},
+		{
+			name:      "Disables an invalid level",
+			level:     Level("invalid"),
+			threshold: TRACE,
+			enabled:   false,
+			compare:   -1,
+		},
+		{
+			name:      "Disables any level with an invalid threshold",
+			level:     FATAL,
+			threshold: Level("invalid"),
+			enabled:   false,
+			compare:   1,
+		},
+	}
+
+	for index, scenario := range scenarios {
+		test.Run(
+			fmt.Sprintf("[%d]-%s", index, scenario.name),
+			func(t *testing.T) {
+				assert.Equal(t, scenario.enabled, scenario.level.Enabled(scenario.threshold), "level enabled")
+				assert.Equal(t, scenario.compare, scenario.level.Compare(scenario.threshold), "level compare")
+			},
+		)
+	}
+}
+
+func TestLoggerLevels(t *testing.T) {
+	var (
+		driver    = newMockDriver()
+		writer    = newMockLogWriter()
+		exitCodes []int
+		log       = New(driver)
+	)
+	exit = func(code int) { exitCodes = append(exitCodes, code) }
+	defer func() { exit = os.Exit }()
+
+	writer.On("Write", mock.AnythingOfType("[]l.Value"))
+	for _, level := range []Level{TRACE, WARN, PANIC, FATAL} {
+		driver.On("Log", level, level.String()+"log").Return(writer)
+	}
+
+	log.Trace(context.Background(), "tracelog")
+	driver.AssertCalled(t, "Log", TRACE, "tracelog")
+
+	log.Warn(context.Background(), "warnlog")
+	driver.AssertCalled(t, "Log", WARN, "warnlog")
+
+	assert.PanicsWithValue(t, "paniclog", func() {
+		log.Panic(context.Background(), "paniclog")
+	})
+	driver.AssertCalled(t, "Log", PANIC, "paniclog")
+
+	log.Fatal(context.Background(), "fatallog")
+	driver.AssertCalled(t, "Log", FATAL, "fatallog")
+	assert.Equal(t, []int{1}, exitCodes, "exit codes")
+
+	SetLoggerDefault(log)
+	Trace(context.Background(), "tracelog")
+	Warn(context.Background(), "warnlog")
+	assert.Panics(t, func() { Panic(context.Background(), "paniclog") })
+	Fatal(context.Background(), "fatallog")
+	assert.Equal(t, []int{1, 1}, exitCodes, "exit codes")
+	writer.AssertNumberOfCalls(t, "Write", 8)
+}
+
+type testConfig struct {
+	Level Level `json:"level"`
+	Out   Out   `json:"out"`
+}
+
+type testMarshal struct {
+	name     string
+	data     string
+	expected testConfig
+	err      bool
+}
+
+func TestLevelOutMarshal(test *testing.T) {
+	scenarios := []testMarshal{
+		{
+			name:     "Unmarshals a valid config",
+			data:     `{"level":"INFO","out":"stderr"}`,
+			expected: testConfig{Level: INFO, Out: STDERR},
+		},
+		{
+			name:     "Unmarshals a file out config",
+			data:     `{"level":"trace","out":"/var/log/app.log"}`,
+			expected: testConfig{Level: TRACE, Out: Out("/var/log/app.log")},
+		},
+		{
+			name: "Does not unmarshal an invalid level",
+			data: `{"level":"eror","out":"stdout"}`,
+			err:  true,
+		},
+		{
+			name: "Does not unmarshal a non string level",
+			data: `{"level":1,"out":"stdout"}`,
+			err:  true,
+		},
+		{
+			name: "Does not unmarshal an invalid out",
+			data: `{"level":"info","out":"tcp://localhost"}`,
+			err:  true,
+		},
+	}
+
+	for index, scenario := range scenarios {
+		test.Run(
+			fmt.Sprintf("[%d]-%s", index, scenario.name),
+			func(t *testing.T) {
+				var config testConfig
+				err := json.Unmarshal([]byte(scenario.data), &config)
+				if scenario.err {
+					assert.Error(t, err, "unmarshal error")
+					return
+				}
+				assert.NoError(t, err, "unmarshal error")
+				assert.Equal(t, scenario.expected, config, "config instance")
+
+				data, err := json.Marshal(config)
+				assert.NoError(t, err, "marshal error")
+				var roundTrip testConfig
+				assert.NoError(t, json.Unmarshal(data, &roundTrip), "round trip error")
+				assert.Equal(t, config, roundTrip, "round trip config")
+
+				levelText, err := config.Level.MarshalText()
+				assert.NoError(t, err, "level marshal text error")
+				var level Level
+				assert.NoError(t, level.UnmarshalText(levelText), "level unmarshal text error")
+				assert.Equal(t, config.Level, level, "level text")
+
+				outText, err := config.Out.MarshalText()
+				assert.NoError(t, err, "out marshal text error")
+				var out Out
+				assert.NoError(t, out.UnmarshalText(outText), "out unmarshal text error")
+				assert.Equal(t, config.Out, out, "out text")
+			},
+		)
+	}
+}
+
+func TestLoggerSetLevel(t *testing.T) {
+	var (
+		driver = newMockDriver()
+		writer = newMockLogWriter()
+		log    = New(driver)
+	)
+	writer.On("Write", mock.AnythingOfType("[]l.Value"))
+	driver.On("Named", "api").Return(driver)
+	driver.On("Log", mock.AnythingOfType("l.Level"), mock.AnythingOfType("string")).Return(writer)
+	child := log.Named("api")
+
+	assert.Equal(t, TRACE, log.Level(), "default level")
+	child.Debug(context.Background(), "debuglog")
+	driver.AssertNumberOfCalls(t, "Log", 1)
+
+	assert.NoError(t, log.SetLevel(INFO), "SetLevel error")
+	assert.Equal(t, INFO, child.Level(), "child level")
+	assert.False(t, child.Enabled(DEBUG), "child debug enabled")
+	child.Debug(context.Background(), "debuglog")
+	log.Debug(context.Background(), "debuglog")
+	driver.AssertNumberOfCalls(t, "Log", 1)
+
+	child.Info(context.Background(), "infolog")
+	driver.AssertNumberOfCalls(t, "Log", 2)
+
+	assert.Error(t, child.SetLevel(Level("invalid")), "SetLevel error")
+	assert.Equal(t, INFO, log.Level(), "level after invalid change")
+}
+
+func TestNewWithLevel(t *testing.T) {
+	level := NewAtomicLevel(WARN)
+	assert.Equal(t, WARN, NewWithLevel(newMockDriver(), level).Level(), "logger level")
+	assert.Equal(t, TRACE, NewWithLevel(newMockDriver(), nil).Level(), "nil logger level")
+}
+
+// testLevelDriver is a driver with its own threshold
+type testLevelDriver struct {
+	*mockDriver
+	level LevelController
+}
+
+func (driver testLevelDriver) LevelController() LevelController {
+	return driver.level
+}
+
+func TestNewLevelDriver(t *testing.T) {
+	level := NewAtomicLevel(WARN)
+	log := New(testLevelDriver{mockDriver: newMockDriver(), level: level})
+	assert.Equal(t, WARN, log.Level(), "driver level")
+	assert.NoError(t, log.SetLevel(ERROR), "SetLevel error")
+	assert.Equal(t, ERROR, level.Level(), "changed driver level")
+	assert.Equal(t, TRACE, New(testLevelDriver{mockDriver: newMockDriver()}).Level(), "driver without level")
+
+	rulesLevel := NewAtomicLevel(WARN)
+	log, err := NewWithLevelRules(testLevelDriver{mockDriver: newMockDriver(), level: rulesLevel}, LevelRules{"db": DEBUG})
+	assert.NoError(t, err, "rules error")
+	assert.Equal(t, WARN, log.Level(), "rules driver level")
+	assert.Equal(t, DEBUG, LevelsOf(log).Level("db"), "rules named level")
+	assert.Equal(t, DEBUG, rulesLevel.Level(), "driver level of the rules")
+	assert.NoError(t, LevelsOf(log).ResetLevel("db"), "ResetLevel error")
+	assert.Equal(t, WARN, rulesLevel.Level(), "driver level after reset")
+}
+
+func TestLoggerClose(t *testing.T) {
+	var (
+		driver = newMockDriver()
+		child  = newMockDriver()
+		ctx    = context.Background()
+		err    = errors.New("err_close")
+	)
+	driver.On("Named", "api").Return(child).Once()
+	driver.On("Sync", ctx).Return(nil).Once()
+	child.On("Close", ctx).Return(err).Once()
+
+	log := New(driver)
+	assert.NoError(t, log.Sync(ctx), "sync logger")
+	assert.Equal(t, err, log.Named("api").Close(ctx), "close child logger")
+	driver.AssertExpectations(t)
+	child.AssertExpectations(t)
+}
@@ -0,0 +1,63 @@
+package l
+
+import (
+	"fmt"
+	"sync/atomic"
+)
+
+// LevelController is a threshold that can be read and changed at runtime
+type LevelController interface {
+	// Level returns the current threshold
+	Level() Level
+	// SetLevel changes the threshold, unknown levels are an error
+	SetLevel(Level) error
+	// Enabled reports whether a message at the provided level passes the current threshold
+	Enabled(Level) bool
+}
+
+// AtomicLevel is a LevelController safe for concurrent use
+type AtomicLevel struct {
+	level atomic.Value
+}
+
+// NewAtomicLevel creates an AtomicLevel with the provided threshold, unknown levels fall back to DEBUG
+func NewAtomicLevel(level Level) *AtomicLevel {
+	atomicLevel := new(AtomicLevel)
+	if err := atomicLevel.SetLevel(level); err != nil {
+		atomicLevel.level.Store(DEBUG)
+	}
+	return atomicLevel
+}
+
+func (a *AtomicLevel) Level() Level {
+	level, _ := a.level.Load().(Level)
+	if level == "" {
+		return DEBUG
+	}
+	return level
+}
+
+func (a *AtomicLevel) SetLevel(level Level) error {
+	if _, known := level.severity(); !known {
+		return fmt.Errorf("err_invalid_level{Level=%q}", level)
+	}
+	a.level.Store(level)
+	return nil
+}
+
+func (a *AtomicLevel) Enabled(level Level) bool {
+	return level.Enabled(a.Level())
+}
+
+func (a *AtomicLevel) String() string {
+	return a.Level().String()
+}
+
+// Set is a utility method for flag system usage
+func (a *AtomicLevel) Set(value string) error {
+	level, err := ParseLevel(value)
+	if err != nil {
+		return err
+	}
+	return a.SetLevel(level)
+}
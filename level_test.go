@@ -0,0 +1,92 @@
+package l
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testAtomicLevel struct {
+	name     string
+	initial  Level
+	level    Level
+	expected Level
+	err      error
+}
+
+func TestAtomicLevel(test *testing.T) {
+	scenarios := []testAtomicLevel{
+		{
+			name:     "Changes the threshold to a higher level",
+			initial:  DEBUG,
+			level:    ERROR,
+			expected: ERROR,
+		},
+		{
+			name:     "Changes the threshold to a lower level",
+			initial:  INFO,
+			level:    TRACE,
+			expected: TRACE,
+		},
+		{
+			name:     "Does not change the threshold to an invalid level",
+			initial:  WARN,
+			level:    Level("invalid"),
+			expected: WARN,
+			err:      errors.New("err_invalid_level{Level=\"invalid\"}"),
+		},
+		{
+			name:     "Creates a DEBUG threshold from an invalid level",
+			initial:  Level("invalid"),
+			level:    Level("invalid"),
+			expected: DEBUG,
+			err:      errors.New("err_invalid_level{Level=\"invalid\"}"),
+		},
+	}
+
+	for index, scenario := range scenarios {
+		test.Run(
+			fmt.Sprintf("[%d]-%s", index, scenario.name),
+			func(t *testing.T) {
+				level := NewAtomicLevel(scenario.initial)
+				assert.Equal(t, scenario.err, level.SetLevel(scenario.level), "SetLevel error")
+				assert.Equal(t, scenario.expected, level.Level(), "level instance")
+				assert.Equal(t, scenario.expected.String(), level.String(), "level string")
+				assert.True(t, level.Enabled(scenario.expected), "expected level enabled")
+				assert.True(t, level.Enabled(FATAL), "fatal level enabled")
+			},
+		)
+	}
+}
+
+func TestAtomicLevelSet(t *testing.T) {
+	level := new(AtomicLevel)
+	assert.Equal(t, DEBUG, level.Level(), "zero value level")
+	assert.NoError(t, level.Set("WARN"), "Set error")
+	assert.Equal(t, WARN, level.Level(), "level instance")
+	assert.Error(t, level.Set("eror"), "Set error")
+	assert.Equal(t, WARN, level.Level(), "level instance")
+}
+
+func TestAtomicLevelConcurrency(t *testing.T) {
+	var (
+		level  = NewAtomicLevel(DEBUG)
+		levels = []Level{TRACE, DEBUG, INFO, WARN, ERROR}
+		group  sync.WaitGroup
+	)
+	for index := 0; index < 100; index++ {
+		group.Add(2)
+		go func(index int) {
+			defer group.Done()
+			assert.NoError(t, level.SetLevel(levels[index%len(levels)]))
+		}(index)
+		go func() {
+			defer group.Done()
+			assert.Contains(t, levels, level.Level())
+		}()
+	}
+	group.Wait()
+}
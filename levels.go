@@ -0,0 +1,304 @@
+package l
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// LevelRegistry keeps the threshold of a root Logger and of every named Logger created from it.
+// A named Logger without its own level inherits the level of its closest named parent, up to the root.
+type LevelRegistry struct {
+	mutex sync.Mutex
+	root  LevelController
+	nodes map[string]*levelNode
+	// driver is the threshold of a LevelDriver, it follows the lowest level of the registry
+	driver      LevelController
+	driverMutex sync.Mutex
+}
+
+// NewLevelRegistry creates a LevelRegistry over the provided root threshold, a nil root is a TRACE AtomicLevel
+func NewLevelRegistry(root LevelController) *LevelRegistry {
+	if root == nil {
+		root = NewAtomicLevel(TRACE)
+	}
+	return &LevelRegistry{
+		root:  root,
+		nodes: make(map[string]*levelNode),
+	}
+}
+
+// newDriverLevelRegistry creates a LevelRegistry whose root starts at the threshold of the driver
+// and which keeps the driver threshold at its lowest level, so the driver writes every entry a named Logger enables
+func newDriverLevelRegistry(driver LevelController) *LevelRegistry {
+	registry := NewLevelRegistry(NewAtomicLevel(driver.Level()))
+	registry.root = rootLevel{LevelController: registry.root, registry: registry}
+	registry.driver = driver
+	return registry
+}
+
+// syncDriver lowers or raises the threshold of the driver to the lowest level of the root and of the named Loggers
+func (registry *LevelRegistry) syncDriver() {
+	if registry.driver == nil {
+		return
+	}
+	registry.driverMutex.Lock()
+	defer registry.driverMutex.Unlock()
+	lowest := registry.root.Level()
+	registry.mutex.Lock()
+	for _, node := range registry.nodes {
+		if level, exists := node.override(); exists && level.Compare(lowest) < 0 {
+			lowest = level
+		}
+	}
+	registry.mutex.Unlock()
+	_ = registry.driver.SetLevel(lowest)
+}
+
+// rootLevel is the root of a LevelRegistry over a driver threshold, a change of the root updates the driver
+type rootLevel struct {
+	LevelController
+	registry *LevelRegistry
+}
+
+func (root rootLevel) SetLevel(level Level) error {
+	if err := root.LevelController.SetLevel(level); err != nil {
+		return err
+	}
+	root.registry.syncDriver()
+	return nil
+}
+
+// Controller returns the LevelController of the named Logger, registering the name when it is new. An empty name is the root.
+func (registry *LevelRegistry) Controller(name string) LevelController {
+	if name == "" {
+		return registry.root
+	}
+	registry.mutex.Lock()
+	defer registry.mutex.Unlock()
+	return registry.node(name)
+}
+
+func (registry *LevelRegistry) node(name string) *levelNode {
+	if node, exists := registry.nodes[name]; exists {
+		return node
+	}
+	var parent LevelController = registry.root
+	if parentName := parentName(name); parentName != "" {
+		parent = registry.node(parentName)
+	}
+	node := &levelNode{parent: parent, registry: registry}
+	registry.nodes[name] = node
+	return node
+}
+
+func (registry *LevelRegistry) lookup(name string) (*levelNode, bool) {
+	registry.mutex.Lock()
+	defer registry.mutex.Unlock()
+	node, exists := registry.nodes[name]
+	return node, exists
+}
+
+// Level returns the effective threshold of the named Logger
+func (registry *LevelRegistry) Level(name string) Level {
+	return registry.Controller(name).Level()
+}
+
+// SetLevel overrides the threshold of the named Logger and of its children without their own level
+func (registry *LevelRegistry) SetLevel(name string, level Level) error {
+	return registry.Controller(name).SetLevel(level)
+}
+
+// Override returns the level set directly on the named Logger and false when it inherits its level
+func (registry *LevelRegistry) Override(name string) (Level, bool) {
+	if name == "" {
+		return registry.root.Level(), true
+	}
+	node, exists := registry.lookup(name)
+	if !exists {
+		return "", false
+	}
+	return node.override()
+}
+
+// ResetLevel removes the level set directly on the named Logger so it inherits its parent level again
+func (registry *LevelRegistry) ResetLevel(name string) error {
+	if name == "" {
+		return errors.New("err_invalid_parameter{Message='Root level cannot be reset'}")
+	}
+	if node, exists := registry.lookup(name); exists {
+		node.reset()
+	}
+	return nil
+}
+
+// Names returns the sorted names registered on the LevelRegistry, the root is not included
+func (registry *LevelRegistry) Names() []string {
+	registry.mutex.Lock()
+	names := make([]string, 0, len(registry.nodes))
+	for name := range registry.nodes {
+		names = append(names, name)
+	}
+	registry.mutex.Unlock()
+	sort.Strings(names)
+	return names
+}
+
+func parentName(name string) string {
+	if index := strings.LastIndex(name, NameSeparator); index >= 0 {
+		return name[:index]
+	}
+	return ""
+}
+
+type levelNode struct {
+	parent   LevelController
+	registry *LevelRegistry
+	level    atomic.Value
+}
+
+func (node *levelNode) override() (Level, bool) {
+	level, _ := node.level.Load().(Level)
+	return level, level != ""
+}
+
+func (node *levelNode) reset() {
+	node.level.Store(Level(""))
+	node.registry.syncDriver()
+}
+
+func (node *levelNode) Level() Level {
+	if level, exists := node.override(); exists {
+		return level
+	}
+	return node.parent.Level()
+}
+
+func (node *levelNode) SetLevel(level Level) error {
+	if _, known := level.severity(); !known {
+		return fmt.Errorf("err_invalid_level{Level=%q}", level)
+	}
+	node.level.Store(level)
+	node.registry.syncDriver()
+	return nil
+}
+
+func (node *levelNode) Enabled(level Level) bool {
+	return level.Enabled(node.Level())
+}
+
+// RootName is the name accepted by LevelRules for the root Logger
+const RootName = "root"
+
+// LevelRules are thresholds by Logger name where the most specific name prefix wins,
+// the text format is `root=info,api=debug,api.db=trace` and a level without a name is the root level
+type LevelRules map[string]Level
+
+// ParseLevelRules parses rules in the `root=info,api=debug,api.db=trace` format
+func ParseLevelRules(value string) (LevelRules, error) {
+	rules := make(LevelRules)
+	for _, rule := range strings.Split(value, ",") {
+		rule = strings.TrimSpace(rule)
+		if rule == "" {
+			continue
+		}
+		name, levelValue := "", rule
+		if index := strings.Index(rule, "="); index >= 0 {
+			name, levelValue = strings.TrimSpace(rule[:index]), strings.TrimSpace(rule[index+1:])
+		}
+		if name == RootName {
+			name = ""
+		}
+		if levelValue == "" {
+			return nil, fmt.Errorf("err_invalid_level_rule{Rule=%q}", rule)
+		}
+		level, err := ParseLevel(levelValue)
+		if err != nil {
+			return nil, err
+		}
+		rules[name] = level
+	}
+	return rules, nil
+}
+
+// String returns the rules in the text format with the root first and the names sorted
+func (rules LevelRules) String() string {
+	names := make([]string, 0, len(rules))
+	for name := range rules {
+		if name != "" {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	parts := make([]string, 0, len(rules))
+	if level, exists := rules[""]; exists {
+		parts = append(parts, RootName+"="+level.String())
+	}
+	for _, name := range names {
+		parts = append(parts, name+"="+rules[name].String())
+	}
+	return strings.Join(parts, ",")
+}
+
+// Set is a utility method for flag system usage
+func (rules *LevelRules) Set(value string) error {
+	parsed, err := ParseLevelRules(value)
+	if err != nil {
+		return err
+	}
+	*rules = parsed
+	return nil
+}
+
+// Rules returns the root level and the level of every named Logger which does not inherit it
+func (registry *LevelRegistry) Rules() LevelRules {
+	rules := LevelRules{"": registry.root.Level()}
+	for _, name := range registry.Names() {
+		if level, overridden := registry.Override(name); overridden {
+			rules[name] = level
+		}
+	}
+	return rules
+}
+
+// SetRules replaces the levels of the registry with the rules, named Loggers without a rule inherit their parent level again.
+// The rules are validated before any change, so an invalid rule leaves the registry untouched.
+func (registry *LevelRegistry) SetRules(rules LevelRules) error {
+	for name, level := range rules {
+		if _, known := level.severity(); !known {
+			return fmt.Errorf("err_invalid_level{Logger=%q, Level=%q}", name, level)
+		}
+	}
+	for _, name := range registry.Names() {
+		if _, exists := rules[name]; !exists {
+			_ = registry.ResetLevel(name)
+		}
+	}
+	for name, level := range rules {
+		if err := registry.SetLevel(name, level); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// String returns the current rules of the registry
+func (registry *LevelRegistry) String() string {
+	if registry == nil || registry.root == nil {
+		return ""
+	}
+	return registry.Rules().String()
+}
+
+// Set is a utility method for flag system usage which replaces the registry levels with the parsed rules
+func (registry *LevelRegistry) Set(value string) error {
+	rules, err := ParseLevelRules(value)
+	if err != nil {
+		return err
+	}
+	return registry.SetRules(rules)
+}
@@ -0,0 +1,254 @@
+package l
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testLevelRegistry struct {
+	name     string
+	root     Level
+	levels   map[string]Level
+	resets   []string
+	expected map[string]Level
+}
+
+func TestLevelRegistry(test *testing.T) {
+	scenarios := []testLevelRegistry{
+		{
+			name: "Inherits the root level",
+			root: INFO,
+			expected: map[string]Level{
+				"":            INFO,
+				"api":         INFO,
+				"api.db.pool": INFO,
+			},
+		},
+		{
+			name: "Inherits the closest parent level",
+			root: INFO,
+			levels: map[string]Level{
+				"api":    DEBUG,
+				"api.db": TRACE,
+			},
+			expected: map[string]Level{
+				"":            INFO,
+				"api":         DEBUG,
+				"api.http":    DEBUG,
+				"api.db":      TRACE,
+				"api.db.pool": TRACE,
+				"worker":      INFO,
+			},
+		},
+		{
+			name: "Changes the root level of every inherited logger",
+			root: INFO,
+			levels: map[string]Level{
+				"":       ERROR,
+				"api.db": TRACE,
+			},
+			expected: map[string]Level{
+				"":            ERROR,
+				"api":         ERROR,
+				"api.db.pool": TRACE,
+			},
+		},
+		{
+			name: "Resets a logger to its inherited level",
+			root: WARN,
+			levels: map[string]Level{
+				"api":    DEBUG,
+				"api.db": TRACE,
+			},
+			resets: []string{"api.db", "unknown"},
+			expected: map[string]Level{
+				"api":         DEBUG,
+				"api.db":      DEBUG,
+				"api.db.pool": DEBUG,
+			},
+		},
+	}
+
+	for index, scenario := range scenarios {
+		test.Run(
+			fmt.Sprintf("[%d]-%s", index, scenario.name),
+			func(t *testing.T) {
+				registry := NewLevelRegistry(NewAtomicLevel(scenario.root))
+				for name, level := range scenario.levels {
+					assert.NoError(t, registry.SetLevel(name, level), "SetLevel error")
+				}
+				for _, name := range scenario.resets {
+					assert.NoError(t, registry.ResetLevel(name), "ResetLevel error")
+				}
+				for name, level := range scenario.expected {
+					assert.Equal(t, level, registry.Level(name), "level of %q", name)
+					assert.True(t, registry.Controller(name).Enabled(level), "enabled level of %q", name)
+				}
+			},
+		)
+	}
+}
+
+func TestLevelRegistryOverride(t *testing.T) {
+	registry := NewLevelRegistry(nil)
+	assert.Equal(t, TRACE, registry.Level(""), "root level")
+
+	level, overridden := registry.Override("")
+	assert.Equal(t, TRACE, level, "root override")
+	assert.True(t, overridden, "root overridden")
+
+	_, overridden = registry.Override("api")
+	assert.False(t, overridden, "unknown logger overridden")
+
+	assert.NoError(t, registry.SetLevel("api.db", INFO), "SetLevel error")
+	level, overridden = registry.Override("api.db")
+	assert.Equal(t, INFO, level, "api.db override")
+	assert.True(t, overridden, "api.db overridden")
+
+	_, overridden = registry.Override("api")
+	assert.False(t, overridden, "api overridden")
+	assert.Equal(t, []string{"api", "api.db"}, registry.Names(), "registered names")
+
+	assert.Equal(t,
+		errors.New("err_invalid_level{Level=\"invalid\"}"),
+		registry.SetLevel("api", Level("invalid")),
+		"SetLevel error",
+	)
+	assert.Equal(t,
+		errors.New("err_invalid_parameter{Message='Root level cannot be reset'}"),
+		registry.ResetLevel(""),
+		"ResetLevel error",
+	)
+}
+
+func TestLoggerLevelRegistry(t *testing.T) {
+	driver := newMockDriver()
+	driver.On("Named", "api").Return(driver)
+	driver.On("Named", "db").Return(driver)
+
+	var (
+		log   = New(driver)
+		api   = log.Named("api")
+		db    = api.Named("db")
+		other = New(driver)
+	)
+	assert.NoError(t, log.SetLevel(INFO), "SetLevel error")
+	assert.NoError(t, api.SetLevel(ERROR), "SetLevel error")
+	assert.Equal(t, INFO, log.Level(), "root level")
+	assert.Equal(t, ERROR, db.Level(), "db level")
+	assert.Equal(t, TRACE, other.Level(), "unrelated level")
+
+	levels := LevelsOf(db)
+	assert.Equal(t, LevelsOf(log), levels, "shared registry")
+	assert.Equal(t, []string{"api", "api.db"}, levels.Names(), "registered names")
+
+	assert.NoError(t, levels.SetLevel("api.db", DEBUG), "SetLevel error")
+	assert.True(t, db.Enabled(DEBUG), "db debug enabled")
+	assert.False(t, api.Enabled(DEBUG), "api debug enabled")
+
+	assert.Nil(t, LevelsOf(nil), "nil logger registry")
+}
+
+type testLevelRules struct {
+	name     string
+	value    string
+	expected LevelRules
+	text     string
+	err      error
+}
+
+func TestLevelRules(test *testing.T) {
+	scenarios := []testLevelRules{
+		{
+			name:     "Parses empty rules",
+			value:    "",
+			expected: LevelRules{},
+			text:     "",
+		},
+		{
+			name:  "Parses root and named rules",
+			value: "root=info,api=debug,api.db=trace",
+			expected: LevelRules{
+				"":       INFO,
+				"api":    DEBUG,
+				"api.db": TRACE,
+			},
+			text: "root=info,api=debug,api.db=trace",
+		},
+		{
+			name:  "Parses a bare root level with spaces and mixed case",
+			value: " WARN , worker = Error ,",
+			expected: LevelRules{
+				"":       WARN,
+				"worker": ERROR,
+			},
+			text: "root=warn,worker=error",
+		},
+		{
+			name:  "Does not parse an invalid level",
+			value: "root=info,api=eror",
+			err:   errors.New("err_invalid_level{Level=\"eror\"}"),
+		},
+		{
+			name:  "Does not parse a rule without level",
+			value: "api=",
+			err:   errors.New("err_invalid_level_rule{Rule=\"api=\"}"),
+		},
+	}
+
+	for index, scenario := range scenarios {
+		test.Run(
+			fmt.Sprintf("[%d]-%s", index, scenario.name),
+			func(t *testing.T) {
+				var rules LevelRules
+				err := rules.Set(scenario.value)
+				assert.Equal(t, scenario.err, err, "LevelRules.Set error")
+				if scenario.err == nil {
+					assert.Equal(t, scenario.expected, rules, "rules instance")
+					assert.Equal(t, scenario.text, rules.String(), "rules text")
+				}
+			},
+		)
+	}
+}
+
+func TestLevelRegistryRules(t *testing.T) {
+	registry := NewLevelRegistry(NewAtomicLevel(INFO))
+	registry.Controller("worker")
+
+	assert.NoError(t, registry.Set("root=warn,api=debug,api.db=trace"), "Set error")
+	assert.Equal(t, "root=warn,api=debug,api.db=trace", registry.String(), "registry text")
+	assert.Equal(t, WARN, registry.Level("worker"), "worker level")
+	assert.Equal(t, DEBUG, registry.Level("api.http"), "api.http level")
+	assert.Equal(t, TRACE, registry.Level("api.db.pool"), "api.db.pool level")
+
+	assert.Error(t, registry.Set("api=info,api.db=eror"), "Set error")
+	assert.Error(t, registry.SetRules(LevelRules{"api": Level("invalid")}), "SetRules error")
+	assert.Equal(t, "root=warn,api=debug,api.db=trace", registry.String(), "registry text after error")
+
+	assert.NoError(t, registry.Set("api.db=error"), "Set error")
+	assert.Equal(t, "root=warn,api.db=error", registry.String(), "registry text")
+	assert.Equal(t, WARN, registry.Level("api"), "api level")
+
+	var flagRegistry *LevelRegistry
+	assert.Equal(t, "", flagRegistry.String(), "nil registry text")
+}
+
+func TestNewWithLevelRules(t *testing.T) {
+	driver := newMockDriver()
+	driver.On("Named", "api").Return(driver)
+
+	rules, err := ParseLevelRules("root=error,api=debug")
+	assert.NoError(t, err, "ParseLevelRules error")
+	log, err := NewWithLevelRules(driver, rules)
+	assert.NoError(t, err, "NewWithLevelRules error")
+	assert.Equal(t, ERROR, log.Level(), "root level")
+	assert.Equal(t, DEBUG, log.Named("api").Level(), "api level")
+
+	log, err = NewWithLevelRules(driver, LevelRules{"api": Level("invalid")})
+	assert.Error(t, err, "NewWithLevelRules error")
+	assert.Nil(t, log, "logger instance")
+}
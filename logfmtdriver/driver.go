@@ -0,0 +1,202 @@
+// Package logfmtdriver is an l.Driver which writes logfmt entries, one line of key=value pairs per entry,
+// for the pipelines which parse logfmt like Heroku and Loki
+package logfmtdriver
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/rjansen/l"
+	"github.com/rjansen/l/internal/driverutil"
+)
+
+const (
+	// packagePath prefixes the frames of the driver, skipped by the stacktraces without caller
+	packagePath = "github.com/rjansen/l/logfmtdriver"
+
+	timeKey       = "time"
+	levelKey      = "level"
+	nameKey       = "logger"
+	callerKey     = "caller"
+	messageKey    = "msg"
+	stacktraceKey = "stack"
+)
+
+// Option customizes the logfmt driver
+type Option func(*options)
+
+type options struct {
+	driverutil.Options
+}
+
+func newOptions(opts []Option) options {
+	options := options{Options: driverutil.NewOptions()}
+	for _, option := range opts {
+		option(&options)
+	}
+	return options
+}
+
+// shared adapts a driverutil.Option to the options of the driver
+func shared(option driverutil.Option) Option {
+	return func(options *options) {
+		option(&options.Options)
+	}
+}
+
+// WithLevel sets the lowest level written by the driver, TRACE by default so the Logger threshold decides
+func WithLevel(level l.Level) Option {
+	return shared(driverutil.WithLevel(level))
+}
+
+// WithCaller enables the caller file and line on the entries, it is disabled by default
+func WithCaller(enabled bool) Option {
+	return shared(driverutil.WithCaller(enabled))
+}
+
+// WithStacktraceLevel sets the level from which entries carry a stacktrace, ERROR by default
+func WithStacktraceLevel(level l.Level) Option {
+	return shared(driverutil.WithStacktraceLevel(level))
+}
+
+// WithClock replaces the time source of the entries
+func WithClock(now func() time.Time) Option {
+	return shared(driverutil.WithClock(now))
+}
+
+type driver struct {
+	sink    *driverutil.Sink
+	options options
+	name    string
+	fields  []byte
+	prefix  string
+	// pending are the values of With from the first lazy value on, they are resolved on every write
+	pending []l.Value
+}
+
+// New creates a Driver which writes logfmt entries to the writer, the writer is not closed by the driver
+func New(writer io.Writer, opts ...Option) l.Driver {
+	return &driver{
+		sink:    driverutil.NewSink(writer),
+		options: newOptions(opts),
+	}
+}
+
+// Open creates a Driver which writes logfmt entries to every destination of the output within its level range,
+// the files are rotated and reopened like the ones of zapdriver.NewLogger and are closed by Close
+func Open(out l.Out, opts ...Option) (l.Driver, error) {
+	sink, err := driverutil.Open(out)
+	if err != nil {
+		return nil, err
+	}
+	return &driver{
+		sink:    sink,
+		options: newOptions(opts),
+	}, nil
+}
+
+func (driver *driver) Log(level l.Level, msg string) l.LogWriter {
+	if !level.Enabled(driver.options.Level) || !driver.sink.Enabled(level) {
+		return nil
+	}
+	entry := &writer{
+		driver: driver,
+		level:  level,
+		msg:    msg,
+		time:   driver.options.Now(),
+	}
+	// the Logger captures the call site only for a CallerWriter, so it is returned only when the entry uses it
+	if driver.options.Caller || level.Enabled(driver.options.StacktraceLevel) {
+		return &callerWriter{writer: entry}
+	}
+	return entry
+}
+
+// With encodes the values once for the child, the lazy values are kept unresolved and encoded on every write
+func (driver *driver) With(values ...l.Value) l.Driver {
+	if len(values) == 0 {
+		return driver
+	}
+	child := *driver
+	values, child.pending = l.SplitWith(driver.pending, values)
+	if len(values) > 0 {
+		enc := encoder{buffer: append([]byte(nil), driver.fields...), prefix: driver.prefix}
+		enc.appendValues(values)
+		child.fields = enc.buffer
+		child.prefix = enc.prefix
+	}
+	return &child
+}
+
+func (driver *driver) Named(name string) l.Driver {
+	if name == "" {
+		return driver
+	}
+	child := *driver
+	child.name = l.JoinName(driver.name, name)
+	return &child
+}
+
+func (driver *driver) Sync(context.Context) error {
+	return driver.sink.Sync()
+}
+
+// Close syncs the writer and closes a file opened by Open, the children share it
+func (driver *driver) Close(context.Context) error {
+	return driver.sink.Close()
+}
+
+type writer struct {
+	driver *driver
+	level  l.Level
+	msg    string
+	time   time.Time
+}
+
+func (writer *writer) Write(values ...l.Value) {
+	writer.write(l.Caller{}, values)
+}
+
+type callerWriter struct {
+	*writer
+}
+
+func (writer *callerWriter) WriteCaller(caller l.Caller, values ...l.Value) {
+	writer.write(caller, values)
+}
+
+func (writer *writer) write(caller l.Caller, values []l.Value) {
+	var (
+		driver = writer.driver
+		buffer = driverutil.GetBuffer()
+		enc    = encoder{buffer: *buffer}
+	)
+	enc.appendKey(timeKey)
+	enc.buffer = writer.time.AppendFormat(enc.buffer, TimeLayout)
+	enc.appendKey(levelKey)
+	enc.buffer = append(enc.buffer, writer.level.String()...)
+	if driver.name != "" {
+		enc.appendString(nameKey, driver.name)
+	}
+	if driver.options.Caller && caller.Defined() {
+		enc.appendString(callerKey, driverutil.ShortCaller(caller.Frame()))
+	}
+	enc.appendString(messageKey, writer.msg)
+	if len(driver.fields) > 0 {
+		enc.buffer = append(enc.buffer, ' ')
+		enc.buffer = append(enc.buffer, driver.fields...)
+	}
+	enc.prefix = driver.prefix
+	enc.appendValues(driver.pending)
+	enc.appendValues(values)
+	if writer.level.Enabled(driver.options.StacktraceLevel) {
+		enc.prefix = ""
+		enc.appendString(stacktraceKey, driverutil.Stacktrace(caller, packagePath))
+	}
+	enc.buffer = append(enc.buffer, '\n')
+
+	driver.sink.Write(writer.level, enc.buffer)
+	*buffer = enc.buffer
+	driverutil.PutBuffer(buffer)
+}
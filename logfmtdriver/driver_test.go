@@ -0,0 +1,218 @@
+package logfmtdriver
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rjansen/l"
+	"github.com/stretchr/testify/assert"
+)
+
+var testTime = time.Date(2019, 10, 1, 12, 30, 15, 123000000, time.UTC)
+
+func testClock() time.Time {
+	return testTime
+}
+
+type testDriver struct {
+	name     string
+	options  []Option
+	log      func(l.Logger)
+	expected []string
+}
+
+func TestDriver(test *testing.T) {
+	scenarios := []testDriver{
+		{
+			name: "Writes an entry",
+			log: func(log l.Logger) {
+				log.Info(context.Background(), "infolog", l.String("key", "value"))
+			},
+			expected: []string{
+				`time=2019-10-01T12:30:15.123Z level=info msg=infolog key=value`,
+			},
+		},
+		{
+			name: "Writes named child entries with values",
+			log: func(log l.Logger) {
+				api := log.Named("api").With(l.String("component", "api"))
+				api.Named("db").With(l.Int("pool", 1)).Debug(context.Background(), "debug log", l.Bool("ok", true))
+				api.Warn(context.Background(), "warnlog")
+			},
+			expected: []string{
+				`time=2019-10-01T12:30:15.123Z level=debug logger=api.db msg="debug log" component=api pool=1 ok=true`,
+				`time=2019-10-01T12:30:15.123Z level=warn logger=api msg=warnlog component=api`,
+			},
+		},
+		{
+			name: "Writes namespaces opened on With as dotted keys",
+			log: func(log l.Logger) {
+				log.With(l.String("id", "request1"), l.Namespace("http")).
+					With(l.String("method", "GET")).
+					Trace(context.Background(), "tracelog", l.Int("status", 200))
+			},
+			expected: []string{
+				`time=2019-10-01T12:30:15.123Z level=trace msg=tracelog id=request1 http.method=GET http.status=200`,
+			},
+		},
+		{
+			name: "Resolves the lazy values of With on every write",
+			log: func(log l.Logger) {
+				var calls int
+				child := log.With(l.String("id", "request1"), l.Lazy("calls", func() interface{} { calls++; return calls }), l.String("method", "GET"))
+				child.Info(context.Background(), "infolog")
+				child.Info(context.Background(), "infolog", l.Int("status", 200))
+			},
+			expected: []string{
+				`time=2019-10-01T12:30:15.123Z level=info msg=infolog id=request1 calls=1 method=GET`,
+				`time=2019-10-01T12:30:15.123Z level=info msg=infolog id=request1 calls=2 method=GET status=200`,
+			},
+		},
+		{
+			name:    "Does not write entries below the driver level",
+			options: []Option{WithLevel(l.INFO)},
+			log: func(log l.Logger) {
+				log.Debug(context.Background(), "debuglog")
+				log.Info(context.Background(), "infolog")
+			},
+			expected: []string{
+				`time=2019-10-01T12:30:15.123Z level=info msg=infolog`,
+			},
+		},
+	}
+
+	for index, scenario := range scenarios {
+		test.Run(
+			fmt.Sprintf("[%d]-%s", index, scenario.name),
+			func(t *testing.T) {
+				var (
+					buffer = new(bytes.Buffer)
+					driver = New(buffer, append([]Option{WithClock(testClock)}, scenario.options...)...)
+				)
+				scenario.log(l.New(driver))
+				assert.NoError(t, driver.Close(context.Background()), "close driver")
+				assert.Equal(t, strings.Join(scenario.expected, "\n")+"\n", buffer.String(), "entries")
+			},
+		)
+	}
+}
+
+func TestDriverRoundTrip(t *testing.T) {
+	var (
+		buffer = new(bytes.Buffer)
+		log    = l.New(New(buffer, WithClock(testClock), WithStacktraceLevel(l.FATAL)))
+	)
+	log.Named("api").With(l.Namespace("http")).Error(context.Background(), "request failed:\n\t\"timeout\"",
+		l.String("path", `/users?name="a b"&c=d`),
+		l.String("empty", ""),
+		l.String("backslash", `C:\logs`),
+		l.String("unicode", "ação"),
+		l.Err(errors.New("line1\nline2")),
+		l.Strings("tags", []string{"a", "b"}),
+		l.Group("response", l.Int("status", 504), l.Duration("elapsed", 1500*time.Millisecond)),
+	)
+
+	pairs, err := parseLogfmt(buffer.String())
+	assert.NoError(t, err, "parse entry")
+	assert.Equal(t,
+		[]pair{
+			{"time", "2019-10-01T12:30:15.123Z"},
+			{"level", "error"},
+			{"logger", "api"},
+			{"msg", "request failed:\n\t\"timeout\""},
+			{"http.path", `/users?name="a b"&c=d`},
+			{"http.empty", ""},
+			{"http.backslash", `C:\logs`},
+			{"http.unicode", "ação"},
+			{"http.error", "line1\nline2"},
+			{"http.tags", "a,b"},
+			{"http.response.status", "504"},
+			{"http.response.elapsed", "1.5s"},
+		},
+		pairs,
+		"pairs",
+	)
+	assert.Equal(t, 1, strings.Count(buffer.String(), "\n"), "single line entry")
+}
+
+func TestDriverCaller(t *testing.T) {
+	var (
+		buffer = new(bytes.Buffer)
+		log    = l.New(New(buffer, WithClock(testClock), WithCaller(true)))
+	)
+	_, _, line, _ := runtime.Caller(0)
+	log.Info(context.Background(), "infolog")
+	assert.Equal(t,
+		fmt.Sprintf("time=2019-10-01T12:30:15.123Z level=info caller=logfmtdriver/driver_test.go:%d msg=infolog\n", line+1),
+		buffer.String(),
+		"entry",
+	)
+}
+
+func TestDriverStacktrace(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	log := l.New(New(buffer, WithClock(testClock), WithStacktraceLevel(l.WARN)))
+	log.With(l.Namespace("http")).Warn(context.Background(), "warnlog", l.String("key", "value"))
+
+	pairs, err := parseLogfmt(buffer.String())
+	assert.NoError(t, err, "parse entry")
+	assert.Len(t, pairs, 5, "pairs")
+	assert.Equal(t, "http.key", pairs[3].key, "field key")
+	assert.Equal(t, "stack", pairs[4].key, "stack key")
+	assert.True(t, strings.HasPrefix(pairs[4].value, "github.com/rjansen/l/logfmtdriver.TestDriverStacktrace\n\t"), "stack %q", pairs[4].value)
+}
+
+func TestOpen(t *testing.T) {
+	for _, out := range []l.Out{l.STDOUT, l.STDERR} {
+		driver, err := Open(out)
+		assert.NoError(t, err, "open %s", out)
+		assert.NotNil(t, driver, "driver %s", out)
+		assert.NoError(t, driver.Close(context.Background()), "close %s", out)
+	}
+	driver, err := Open(l.Out("/invalid/path/app.log"))
+	assert.Error(t, err, "open error")
+	assert.Nil(t, driver, "driver instance")
+
+	dir, err := ioutil.TempDir("", "logfmtdriver")
+	assert.NoError(t, err, "temp dir")
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "app.log")
+	driver, err = Open(l.Out("file://"+path), WithClock(testClock))
+	assert.NoError(t, err, "open file")
+	l.New(driver).Info(context.Background(), "infolog")
+	assert.NoError(t, driver.Close(context.Background()), "close driver")
+
+	data, err := ioutil.ReadFile(path)
+	assert.NoError(t, err, "file entries")
+	assert.Equal(t, "time=2019-10-01T12:30:15.123Z level=info msg=infolog\n", string(data), "file entry")
+}
+
+func BenchmarkDriver(b *testing.B) {
+	var (
+		log = l.New(New(ioutil.Discard))
+		ctx = context.Background()
+		now = time.Now()
+	)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for index := 0; index < b.N; index++ {
+		log.Info(ctx, "benchmark",
+			l.String("string", "value"),
+			l.Int("int", index),
+			l.Float64("float", 999.99),
+			l.Bool("bool", true),
+			l.Time("time", now),
+			l.Duration("duration", time.Second),
+		)
+	}
+}
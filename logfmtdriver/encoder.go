@@ -0,0 +1,221 @@
+package logfmtdriver
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/rjansen/l"
+)
+
+// TimeLayout is the RFC3339 layout with milliseconds of the times written by the driver
+const TimeLayout = "2006-01-02T15:04:05.000Z07:00"
+
+const hex = "0123456789abcdef"
+
+// encoder appends logfmt pairs to a buffer, the prefix holds the dotted path of the groups and namespaces of the keys
+type encoder struct {
+	buffer []byte
+	prefix string
+}
+
+func (enc *encoder) appendKey(key string) {
+	if len(enc.buffer) > 0 {
+		enc.buffer = append(enc.buffer, ' ')
+	}
+	enc.buffer = appendKey(enc.buffer, enc.prefix+key)
+	enc.buffer = append(enc.buffer, '=')
+}
+
+func (enc *encoder) appendString(key string, value string) {
+	enc.appendKey(key)
+	enc.buffer = appendString(enc.buffer, value)
+}
+
+func (enc *encoder) appendValues(values []l.Value) {
+	for _, value := range values {
+		enc.appendValue(value.Resolve())
+	}
+}
+
+func (enc *encoder) appendValue(value l.Value) {
+	switch value.Kind() {
+	case l.StringKind:
+		enc.appendString(value.Name(), value.AsString())
+	case l.Int64Kind:
+		enc.appendKey(value.Name())
+		enc.buffer = strconv.AppendInt(enc.buffer, value.AsInt64(), 10)
+	case l.Uint64Kind:
+		enc.appendKey(value.Name())
+		enc.buffer = strconv.AppendUint(enc.buffer, value.AsUint64(), 10)
+	case l.Float64Kind:
+		enc.appendKey(value.Name())
+		enc.buffer = strconv.AppendFloat(enc.buffer, value.AsFloat64(), 'f', -1, 64)
+	case l.BoolKind:
+		enc.appendKey(value.Name())
+		enc.buffer = strconv.AppendBool(enc.buffer, value.AsBool())
+	case l.TimeKind:
+		enc.appendKey(value.Name())
+		enc.buffer = value.AsTime().AppendFormat(enc.buffer, TimeLayout)
+	case l.DurationKind:
+		enc.appendKey(value.Name())
+		enc.buffer = append(enc.buffer, value.AsDuration().String()...)
+	case l.ErrorKind:
+		if err := value.AsError(); err != nil {
+			enc.appendString(value.Name(), err.Error())
+		}
+	case l.BytesKind:
+		enc.appendKey(value.Name())
+		enc.buffer = append(enc.buffer, base64.StdEncoding.EncodeToString(value.AsBytes())...)
+	case l.StringsKind:
+		enc.appendKey(value.Name())
+		enc.buffer = appendStrings(enc.buffer, value.AsStrings())
+	case l.GroupKind:
+		enc.appendGroup(value.Name(), value.AsGroup())
+	case l.NamespaceKind:
+		enc.prefix += value.Name() + "."
+	default:
+		enc.appendAny(value.Name(), value.Any())
+	}
+}
+
+func (enc *encoder) appendGroup(name string, values []l.Value) {
+	if len(values) == 0 {
+		return
+	}
+	if name == "" {
+		enc.appendValues(values)
+		return
+	}
+	prefix := enc.prefix
+	enc.prefix += name + "."
+	enc.appendValues(values)
+	enc.prefix = prefix
+}
+
+// appendAny encodes the common types like their l.Value and any other type with the fmt %+v verb
+func (enc *encoder) appendAny(name string, value interface{}) {
+	switch value := value.(type) {
+	case string:
+		enc.appendValue(l.String(name, value))
+	case int:
+		enc.appendValue(l.Int64(name, int64(value)))
+	case int32:
+		enc.appendValue(l.Int64(name, int64(value)))
+	case int64:
+		enc.appendValue(l.Int64(name, value))
+	case uint:
+		enc.appendValue(l.Uint64(name, uint64(value)))
+	case uint32:
+		enc.appendValue(l.Uint64(name, uint64(value)))
+	case uint64:
+		enc.appendValue(l.Uint64(name, value))
+	case float32:
+		enc.appendKey(name)
+		enc.buffer = strconv.AppendFloat(enc.buffer, float64(value), 'f', -1, 32)
+	case float64:
+		enc.appendValue(l.Float64(name, value))
+	case bool:
+		enc.appendValue(l.Bool(name, value))
+	case time.Time:
+		enc.appendValue(l.Time(name, value))
+	case time.Duration:
+		enc.appendValue(l.Duration(name, value))
+	case error:
+		enc.appendValue(l.NamedErr(name, value))
+	case []byte:
+		enc.appendValue(l.Bytes(name, value))
+	case []string:
+		enc.appendValue(l.Strings(name, value))
+	case fmt.Stringer:
+		enc.appendValue(l.String(name, value.String()))
+	default:
+		enc.appendString(name, fmt.Sprintf("%+v", value))
+	}
+}
+
+// appendStrings appends the values separated by commas
+func appendStrings(buffer []byte, values []string) []byte {
+	return appendString(buffer, strings.Join(values, ","))
+}
+
+// appendKey appends the key replacing the characters which would break the pair, like spaces and equal signs, with underscores
+func appendKey(buffer []byte, key string) []byte {
+	if key == "" {
+		return append(buffer, '_')
+	}
+	for _, char := range key {
+		if char <= ' ' || char == '=' || char == '"' || char == utf8.RuneError || !unicode.IsPrint(char) {
+			buffer = append(buffer, '_')
+			continue
+		}
+		buffer = utf8.AppendRune(buffer, char)
+	}
+	return buffer
+}
+
+// appendString appends the value as is, or quoted and escaped when it is empty or has spaces, equal signs,
+// quotes, backslashes or non printable characters, invalid utf8 is replaced like zap does
+func appendString(buffer []byte, value string) []byte {
+	if !needsQuote(value) {
+		return append(buffer, value...)
+	}
+	buffer = append(buffer, '"')
+	start := 0
+	for index := 0; index < len(value); {
+		if char := value[index]; char < utf8.RuneSelf {
+			if char >= 0x20 && char != 0x7f && char != '\\' && char != '"' {
+				index++
+				continue
+			}
+			buffer = append(buffer, value[start:index]...)
+			switch char {
+			case '\\', '"':
+				buffer = append(buffer, '\\', char)
+			case '\n':
+				buffer = append(buffer, '\\', 'n')
+			case '\r':
+				buffer = append(buffer, '\\', 'r')
+			case '\t':
+				buffer = append(buffer, '\\', 't')
+			default:
+				buffer = append(buffer, '\\', 'u', '0', '0', hex[char>>4], hex[char&0xF])
+			}
+			index++
+			start = index
+			continue
+		}
+		char, size := utf8.DecodeRuneInString(value[index:])
+		if char == utf8.RuneError && size == 1 {
+			buffer = append(buffer, value[start:index]...)
+			buffer = append(buffer, `\ufffd`...)
+			index += size
+			start = index
+			continue
+		}
+		index += size
+	}
+	buffer = append(buffer, value[start:]...)
+	return append(buffer, '"')
+}
+
+func needsQuote(value string) bool {
+	if value == "" {
+		return true
+	}
+	for index := 0; index < len(value); {
+		char, size := utf8.DecodeRuneInString(value[index:])
+		if char == utf8.RuneError && size == 1 {
+			return true
+		}
+		if char <= ' ' || char == '=' || char == '"' || char == '\\' || !unicode.IsPrint(char) {
+			return true
+		}
+		index += size
+	}
+	return false
+}
@@ -0,0 +1,108 @@
+package logfmtdriver
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/rjansen/l"
+	"github.com/stretchr/testify/assert"
+)
+
+type testStringer struct{}
+
+func (testStringer) String() string {
+	return "stringer value"
+}
+
+type testEncoder struct {
+	name     string
+	values   []l.Value
+	expected string
+}
+
+func TestEncoder(test *testing.T) {
+	scenarios := []testEncoder{
+		{
+			name: "Encodes typed values",
+			values: []l.Value{
+				l.String("string", "value"),
+				l.Int("int", -1),
+				l.Uint64("uint", 1),
+				l.Float64("float", 0.5),
+				l.Float64("nan", math.NaN()),
+				l.Bool("bool", true),
+				l.Time("time", time.Date(2019, 10, 1, 12, 0, 0, 0, time.FixedZone("BRT", -3*60*60))),
+				l.Duration("duration", time.Second),
+				l.Err(errors.New("connection refused")),
+				l.Err(nil),
+				l.Bytes("bytes", []byte("bytes1")),
+				l.Strings("strings", []string{"a", "b c"}),
+				l.Lazy("lazy", func() interface{} { return "lazy1" }),
+			},
+			expected: `string=value int=-1 uint=1 float=0.5 nan=NaN bool=true time=2019-10-01T12:00:00.000-03:00 duration=1s` +
+				` error="connection refused" bytes=Ynl0ZXMx strings="a,b c" lazy=lazy1`,
+		},
+		{
+			name: "Quotes and escapes strings",
+			values: []l.Value{
+				l.String("empty", ""),
+				l.String("space", "a b"),
+				l.String("equal", "a=b"),
+				l.String("quote", `a"b`),
+				l.String("backslash", `a\b`),
+				l.String("newline", "a\nb\r\tc"),
+				l.String("control", "a\x00b\x7f"),
+				l.String("invalid", "a\xffb"),
+				l.String("unicode", "ação"),
+			},
+			expected: `empty="" space="a b" equal="a=b" quote="a\"b" backslash="a\\b" newline="a\nb\r\tc"` +
+				` control="a\u0000b\u007f" invalid="a\ufffdb" unicode=ação`,
+		},
+		{
+			name: "Replaces invalid key characters",
+			values: []l.Value{
+				l.String("a key", "1"),
+				l.String("a=key", "2"),
+				l.String(`a"key`, "3"),
+				l.String("", "4"),
+			},
+			expected: `a_key=1 a_key=2 a_key=3 _=4`,
+		},
+		{
+			name: "Encodes any values",
+			values: []l.Value{
+				l.NewValue("int", 1),
+				l.NewValue("float32", float32(0.5)),
+				l.NewValue("stringer", testStringer{}),
+				l.NewValue("map", map[string]int{"a": 1}),
+				l.NewValue("nil", nil),
+			},
+			expected: `int=1 float32=0.5 stringer="stringer value" map=map[a:1] nil=<nil>`,
+		},
+		{
+			name: "Encodes groups and namespaces as dotted keys",
+			values: []l.Value{
+				l.Group("http", l.String("method", "GET"), l.Group("response", l.Int("status", 200))),
+				l.Group("empty"),
+				l.Group("", l.Bool("inline", true)),
+				l.Namespace("user"),
+				l.String("id", "user1"),
+			},
+			expected: `http.method=GET http.response.status=200 inline=true user.id=user1`,
+		},
+	}
+
+	for index, scenario := range scenarios {
+		test.Run(
+			fmt.Sprintf("[%d]-%s", index, scenario.name),
+			func(t *testing.T) {
+				var enc encoder
+				enc.appendValues(scenario.values)
+				assert.Equal(t, scenario.expected, string(enc.buffer), "pairs")
+			},
+		)
+	}
+}
@@ -0,0 +1,111 @@
+package logfmtdriver
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// pair is a logfmt key and its unquoted value
+type pair struct {
+	key   string
+	value string
+}
+
+// parseLogfmt parses a logfmt line, a key without equal sign has an empty value and quoted values are unescaped
+func parseLogfmt(line string) ([]pair, error) {
+	var (
+		pairs []pair
+		index int
+	)
+	for {
+		for index < len(line) && (line[index] == ' ' || line[index] == '\n') {
+			index++
+		}
+		if index >= len(line) {
+			return pairs, nil
+		}
+		start := index
+		for index < len(line) && line[index] != '=' && line[index] != ' ' && line[index] != '\n' {
+			if line[index] == '"' {
+				return nil, fmt.Errorf("err_invalid_logfmt{Offset=%d, Message='quote in key'}", index)
+			}
+			index++
+		}
+		key := line[start:index]
+		if index >= len(line) || line[index] != '=' {
+			pairs = append(pairs, pair{key: key})
+			continue
+		}
+		index++
+		if index < len(line) && line[index] == '"' {
+			start = index
+			for index++; index < len(line) && line[index] != '"'; index++ {
+				if line[index] == '\\' {
+					index++
+				}
+			}
+			if index >= len(line) {
+				return nil, fmt.Errorf("err_invalid_logfmt{Offset=%d, Message='unterminated quoted value'}", start)
+			}
+			index++
+			value, err := strconv.Unquote(line[start:index])
+			if err != nil {
+				return nil, fmt.Errorf("err_invalid_logfmt{Offset=%d, Message='%s'}", start, err)
+			}
+			pairs = append(pairs, pair{key: key, value: value})
+			continue
+		}
+		start = index
+		for index < len(line) && line[index] != ' ' && line[index] != '\n' {
+			index++
+		}
+		pairs = append(pairs, pair{key: key, value: line[start:index]})
+	}
+}
+
+type testParser struct {
+	name     string
+	line     string
+	expected []pair
+	err      error
+}
+
+func TestParser(test *testing.T) {
+	scenarios := []testParser{
+		{
+			name:     "Parses bare and quoted values",
+			line:     `time=2019-10-01T12:30:15.123Z msg="info log" empty="" flag escaped="a\"b\\c\nd"` + "\n",
+			expected: []pair{{"time", "2019-10-01T12:30:15.123Z"}, {"msg", "info log"}, {"empty", ""}, {"flag", ""}, {"escaped", "a\"b\\c\nd"}},
+		},
+		{
+			name:     "Parses an empty line",
+			line:     "\n",
+			expected: nil,
+		},
+		{
+			name: "Fails on an unterminated quoted value",
+			line: `msg="info log`,
+			err:  errors.New("err_invalid_logfmt{Offset=4, Message='unterminated quoted value'}"),
+		},
+		{
+			name: "Fails on a quote in a key",
+			line: `"msg"=log`,
+			err:  errors.New("err_invalid_logfmt{Offset=0, Message='quote in key'}"),
+		},
+	}
+
+	for index, scenario := range scenarios {
+		test.Run(
+			fmt.Sprintf("[%d]-%s", index, scenario.name),
+			func(t *testing.T) {
+				pairs, err := parseLogfmt(scenario.line)
+				assert.Equal(t, scenario.err, err, "parse error")
+				assert.Equal(t, scenario.expected, pairs, "pairs")
+			},
+		)
+	}
+}
@@ -15,6 +15,10 @@ func NewMockLogger() *MockLogger {
 	return new(MockLogger)
 }
 
+func (mock *MockLogger) Trace(ctx context.Context, msg string, values ...l.Value) {
+	mock.Called(ctx, msg, values)
+}
+
 func (mock *MockLogger) Debug(ctx context.Context, msg string, values ...l.Value) {
 	mock.Called(ctx, msg, values)
 }
@@ -23,6 +27,53 @@ func (mock *MockLogger) Info(ctx context.Context, msg string, values ...l.Value)
 	mock.Called(ctx, msg, values)
 }
 
+func (mock *MockLogger) Warn(ctx context.Context, msg string, values ...l.Value) {
+	mock.Called(ctx, msg, values)
+}
+
 func (mock *MockLogger) Error(ctx context.Context, msg string, values ...l.Value) {
 	mock.Called(ctx, msg, values)
 }
+
+func (mock *MockLogger) Panic(ctx context.Context, msg string, values ...l.Value) {
+	mock.Called(ctx, msg, values)
+}
+
+func (mock *MockLogger) Fatal(ctx context.Context, msg string, values ...l.Value) {
+	mock.Called(ctx, msg, values)
+}
+
+func (mock *MockLogger) With(values ...l.Value) l.Logger {
+	args := mock.Called(values)
+	return args.Get(0).(l.Logger)
+}
+
+func (mock *MockLogger) Named(name string) l.Logger {
+	args := mock.Called(name)
+	return args.Get(0).(l.Logger)
+}
+
+func (mock *MockLogger) Level() l.Level {
+	args := mock.Called()
+	return args.Get(0).(l.Level)
+}
+
+func (mock *MockLogger) SetLevel(level l.Level) error {
+	args := mock.Called(level)
+	return args.Error(0)
+}
+
+func (mock *MockLogger) Enabled(level l.Level) bool {
+	args := mock.Called(level)
+	return args.Bool(0)
+}
+
+func (mock *MockLogger) Sync(ctx context.Context) error {
+	args := mock.Called(ctx)
+	return args.Error(0)
+}
+
+func (mock *MockLogger) Close(ctx context.Context) error {
+	args := mock.Called(ctx)
+	return args.Error(0)
+}
@@ -14,15 +14,65 @@ import (
 func TestMockLogger(t *testing.T) {
 	logger := NewMockLogger()
 	assert.Implements(t, (*l.Logger)(nil), logger)
+	logger.On("Trace", mock.Anything, mock.Anything, mock.Anything).Once()
 	logger.On("Debug", mock.Anything, mock.Anything, mock.Anything).Once()
 	logger.On("Info", mock.Anything, mock.Anything, mock.Anything).Once()
+	logger.On("Warn", mock.Anything, mock.Anything, mock.Anything).Once()
 	logger.On("Error", mock.Anything, mock.Anything, mock.Anything).Once()
+	logger.On("Panic", mock.Anything, mock.Anything, mock.Anything).Once()
+	logger.On("Fatal", mock.Anything, mock.Anything, mock.Anything).Once()
 
+	logger.Trace(context.Background(), "trace", l.NewValue("key", "value"))
 	logger.Debug(context.Background(), "debug", l.NewValue("key", "value"))
 	logger.Info(context.Background(), "info", l.NewValue("key", "value"))
+	logger.Warn(context.Background(), "warn", l.NewValue("key", "value"))
 	logger.Error(context.Background(), "error",
 		l.NewValue("key", "value"), l.NewValue("error", errors.New("err_mock")),
 	)
+	logger.Panic(context.Background(), "panic", l.NewValue("key", "value"))
+	logger.Fatal(context.Background(), "fatal", l.NewValue("key", "value"))
+
+	logger.AssertExpectations(t)
+}
+
+func TestMockLoggerChild(t *testing.T) {
+	var (
+		logger = NewMockLogger()
+		child  = NewMockLogger()
+	)
+	logger.On("Named", "api").Return(child).Once()
+	child.On("With", []l.Value{l.NewValue("key", "value")}).Return(child).Once()
+
+	assert.Equal(t, child, logger.Named("api").With(l.NewValue("key", "value")))
+
+	logger.AssertExpectations(t)
+	child.AssertExpectations(t)
+}
+
+func TestMockLoggerLevel(t *testing.T) {
+	logger := NewMockLogger()
+	logger.On("Level").Return(l.INFO).Once()
+	logger.On("SetLevel", l.DEBUG).Return(nil).Once()
+	logger.On("Enabled", l.DEBUG).Return(true).Once()
+
+	assert.Equal(t, l.INFO, logger.Level())
+	assert.NoError(t, logger.SetLevel(l.DEBUG))
+	assert.True(t, logger.Enabled(l.DEBUG))
+
+	logger.AssertExpectations(t)
+}
+
+func TestMockLoggerClose(t *testing.T) {
+	var (
+		logger = NewMockLogger()
+		ctx    = context.Background()
+		err    = errors.New("err_close")
+	)
+	logger.On("Sync", ctx).Return(nil).Once()
+	logger.On("Close", ctx).Return(err).Once()
+
+	assert.NoError(t, logger.Sync(ctx))
+	assert.Equal(t, err, logger.Close(ctx))
 
 	logger.AssertExpectations(t)
 }
@@ -0,0 +1,288 @@
+package l
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	// JSONEncoding writes one json object per entry, it is the default encoding
+	JSONEncoding Encoding = "json"
+	// ConsoleEncoding writes the entries in a line oriented layout for humans
+	ConsoleEncoding Encoding = "console"
+)
+
+// Encoding is the format of the entries written to a destination
+type Encoding string
+
+// OutSeparator separates the destinations of an Out, like stdout?encoding=console,/var/log/app.log
+const OutSeparator = ","
+
+const (
+	outLevelParameter    = "level"
+	outBelowParameter    = "below"
+	outEncodingParameter = "encoding"
+
+	outMaxSizeParameter    = "maxsize"
+	outMaxAgeParameter     = "maxage"
+	outMaxBackupsParameter = "maxbackups"
+	outCompressParameter   = "compress"
+	outRotateParameter     = "rotate"
+)
+
+// Destination is one output of an Out, it has an optional level range and encoding
+// written as query parameters, like /var/log/app.log?level=warn&encoding=json
+type Destination struct {
+	// Out is the standard stream, file path or file URL of the destination
+	Out Out
+	// Level is the minimum level written to the destination, empty writes every level enabled by the logger
+	Level Level
+	// Below is the level from which entries are not written to the destination, empty writes up to FATAL
+	Below Level
+	// Encoding replaces the logger encoding for the destination when it is not empty
+	Encoding Encoding
+	// Rotation rotates a file destination,
+	// like file:///var/log/app.log?maxsize=100MB&maxage=7d&maxbackups=10&compress=gzip&rotate=daily
+	Rotation Rotation
+}
+
+func (destination Destination) String() string {
+	parameters := url.Values{}
+	if destination.Level != "" {
+		parameters.Set(outLevelParameter, destination.Level.String())
+	}
+	if destination.Below != "" {
+		parameters.Set(outBelowParameter, destination.Below.String())
+	}
+	if destination.Encoding != "" {
+		parameters.Set(outEncodingParameter, string(destination.Encoding))
+	}
+	rotation := destination.Rotation
+	if rotation.MaxSize > 0 {
+		parameters.Set(outMaxSizeParameter, formatSize(rotation.MaxSize))
+	}
+	if rotation.MaxAge > 0 {
+		parameters.Set(outMaxAgeParameter, formatAge(rotation.MaxAge))
+	}
+	if rotation.MaxBackups > 0 {
+		parameters.Set(outMaxBackupsParameter, strconv.Itoa(rotation.MaxBackups))
+	}
+	if rotation.Compression != "" {
+		parameters.Set(outCompressParameter, rotation.Compression)
+	}
+	if rotation.Schedule != "" {
+		parameters.Set(outRotateParameter, rotation.Schedule)
+	}
+	if len(parameters) == 0 {
+		return destination.Out.String()
+	}
+	return destination.Out.String() + "?" + parameters.Encode()
+}
+
+// NewOut creates an Out which writes to every destination
+func NewOut(destinations ...Destination) Out {
+	values := make([]string, len(destinations))
+	for index, destination := range destinations {
+		values[index] = destination.String()
+	}
+	return Out(strings.Join(values, OutSeparator))
+}
+
+// NewSplitOut creates an Out which writes the levels below the threshold to the low output
+// and the threshold and higher levels to the high output, like stdout?below=error,stderr?level=error
+func NewSplitOut(low Out, high Out, threshold Level) Out {
+	return NewOut(
+		Destination{Out: low, Below: threshold},
+		Destination{Out: high, Level: threshold},
+	)
+}
+
+// Destinations splits the output in its destinations, a file path can not have commas
+func (o Out) Destinations() ([]Destination, error) {
+	values := strings.Split(string(o), OutSeparator)
+	destinations := make([]Destination, len(values))
+	for index, value := range values {
+		destination, err := parseDestination(value)
+		if err != nil {
+			return nil, err
+		}
+		destinations[index] = destination
+	}
+	return destinations, nil
+}
+
+func parseDestination(value string) (Destination, error) {
+	index := strings.IndexByte(value, '?')
+	if index < 0 {
+		return Destination{Out: Out(value)}, nil
+	}
+	parameters, err := url.ParseQuery(value[index+1:])
+	if err != nil {
+		return Destination{}, fmt.Errorf("err_invalid_out{Out=%q, Message='invalid parameters'}", value)
+	}
+	names := make([]string, 0, len(parameters))
+	for name := range parameters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	destination := Destination{Out: Out(value[:index])}
+	for _, name := range names {
+		parameter := parameters.Get(name)
+		switch name {
+		case outLevelParameter, outBelowParameter:
+			if parameter == "" {
+				return Destination{}, fmt.Errorf("err_invalid_out{Out=%q, Message='empty %s'}", value, name)
+			}
+			level, err := ParseLevel(parameter)
+			if err != nil {
+				return Destination{}, fmt.Errorf("err_invalid_out{Out=%q, Message='invalid %s'}", value, name)
+			}
+			if name == outLevelParameter {
+				destination.Level = level
+			} else {
+				destination.Below = level
+			}
+		case outEncodingParameter:
+			encoding := Encoding(strings.ToLower(parameter))
+			if encoding != JSONEncoding && encoding != ConsoleEncoding {
+				return Destination{}, fmt.Errorf("err_invalid_out{Out=%q, Message='invalid encoding'}", value)
+			}
+			destination.Encoding = encoding
+		case outMaxSizeParameter:
+			size, err := ParseSize(parameter)
+			if err != nil {
+				return Destination{}, fmt.Errorf("err_invalid_out{Out=%q, Message='invalid %s'}", value, name)
+			}
+			destination.Rotation.MaxSize = size
+		case outMaxAgeParameter:
+			age, err := ParseAge(parameter)
+			if err != nil {
+				return Destination{}, fmt.Errorf("err_invalid_out{Out=%q, Message='invalid %s'}", value, name)
+			}
+			destination.Rotation.MaxAge = age
+		case outMaxBackupsParameter:
+			backups, err := strconv.Atoi(parameter)
+			if err != nil || backups < 0 {
+				return Destination{}, fmt.Errorf("err_invalid_out{Out=%q, Message='invalid %s'}", value, name)
+			}
+			destination.Rotation.MaxBackups = backups
+		case outCompressParameter:
+			if parameter != GzipCompression {
+				return Destination{}, fmt.Errorf("err_invalid_out{Out=%q, Message='invalid %s'}", value, name)
+			}
+			destination.Rotation.Compression = parameter
+		case outRotateParameter:
+			if parameter != DailyRotation {
+				return Destination{}, fmt.Errorf("err_invalid_out{Out=%q, Message='invalid %s'}", value, name)
+			}
+			destination.Rotation.Schedule = parameter
+		default:
+			return Destination{}, fmt.Errorf("err_invalid_out{Out=%q, Message='unknown parameter %s'}", value, name)
+		}
+	}
+	if destination.Below != "" && destination.Level.Compare(destination.Below) >= 0 {
+		return Destination{}, fmt.Errorf("err_invalid_out{Out=%q, Message='below must be higher than level'}", value)
+	}
+	return destination, nil
+}
+
+// isFile reports whether the destination is a file path or a file URL
+func (destination Destination) isFile() bool {
+	out := destination.Out.String()
+	if destination.Out == STDOUT || destination.Out == STDERR {
+		return false
+	}
+	return !strings.Contains(out, "://") || strings.HasPrefix(out, "file://")
+}
+
+// filePath returns the path of a file destination
+func (destination Destination) filePath() string {
+	return strings.TrimPrefix(destination.Out.String(), "file://")
+}
+
+// Output is an opened destination, the drivers write the entries to it with WriteEntry
+type Output interface {
+	io.Writer
+	Sync() error
+	Close() error
+}
+
+// StderrOutput is implemented by the outputs writing to os.Stderr, WriteEntry does not write their failed entries
+// to os.Stderr again. A wrapper of an output, like a lock, implements it to keep the output marked
+type StderrOutput interface {
+	Stderr() bool
+}
+
+// IsStderr reports whether the writer is os.Stderr or an output marked as os.Stderr by StderrOutput
+func IsStderr(writer io.Writer) bool {
+	if output, ok := writer.(StderrOutput); ok {
+		return output.Stderr()
+	}
+	return writer == io.Writer(os.Stderr)
+}
+
+// stdOutput is a standard stream, it is neither synced, because fsync fails on terminals and pipes, nor closed
+type stdOutput struct {
+	io.Writer
+	stderr bool
+}
+
+func (output stdOutput) Stderr() bool {
+	return output.stderr
+}
+
+func (stdOutput) Sync() error {
+	return nil
+}
+
+func (stdOutput) Close() error {
+	return nil
+}
+
+// OpenDestination opens the output of the destination, a file is opened with the destination Rotation
+// and is opened again by Reopen and ReopenOnSignal
+func OpenDestination(destination Destination) (Output, error) {
+	switch destination.Out {
+	case STDOUT:
+		return stdOutput{Writer: os.Stdout}, nil
+	case STDERR:
+		return stdOutput{Writer: os.Stderr, stderr: true}, nil
+	}
+	if !destination.isFile() {
+		return nil, fmt.Errorf("err_invalid_out{Out=%q, Message='unsupported scheme'}", destination.Out)
+	}
+	if destination.Rotation.Enabled() {
+		file, err := OpenRotatingFile(destination.filePath(), destination.Rotation)
+		if err != nil {
+			return nil, err
+		}
+		return file, nil
+	}
+	file, err := OpenReopenableFile(destination.filePath())
+	if err != nil {
+		return nil, err
+	}
+	return file, nil
+}
+
+// parseSingleOut parses the output of one destination ignoring the spaces around it
+func parseSingleOut(value string) (Out, error) {
+	out := strings.TrimSpace(value)
+	switch strings.ToLower(out) {
+	case "stdout":
+		return STDOUT, nil
+	case "stderr":
+		return STDERR, nil
+	case "":
+		return "", fmt.Errorf("err_invalid_out{Out=%q}", value)
+	}
+	if index := strings.Index(out, "://"); index >= 0 && out[:index] != "file" {
+		return "", fmt.Errorf("err_invalid_out{Out=%q, Message='unsupported scheme'}", out)
+	}
+	return Out(out), nil
+}
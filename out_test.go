@@ -0,0 +1,257 @@
+package l
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testDestinations struct {
+	name         string
+	output       string
+	expected     Out
+	destinations []Destination
+	err          error
+}
+
+func TestOutDestinations(test *testing.T) {
+	scenarios := []testDestinations{
+		{
+			name:         "Parses a single destination",
+			output:       "/var/log/app.log",
+			expected:     Out("/var/log/app.log"),
+			destinations: []Destination{{Out: Out("/var/log/app.log")}},
+		},
+		{
+			name:     "Parses several destinations",
+			output:   "STDOUT?encoding=console, file:///var/log/app.log?level=INFO&encoding=json,stderr?level=error",
+			expected: Out("stdout?encoding=console,file:///var/log/app.log?encoding=json&level=info,stderr?level=error"),
+			destinations: []Destination{
+				{Out: STDOUT, Encoding: ConsoleEncoding},
+				{Out: Out("file:///var/log/app.log"), Level: INFO, Encoding: JSONEncoding},
+				{Out: STDERR, Level: ERROR},
+			},
+		},
+		{
+			name:     "Parses a level split",
+			output:   "stdout?below=ERROR,stderr?level=error",
+			expected: NewSplitOut(STDOUT, STDERR, ERROR),
+			destinations: []Destination{
+				{Out: STDOUT, Below: ERROR},
+				{Out: STDERR, Level: ERROR},
+			},
+		},
+		{
+			name:         "Parses a level range",
+			output:       "/var/log/app.log?level=info&below=error",
+			expected:     Out("/var/log/app.log?below=error&level=info"),
+			destinations: []Destination{{Out: Out("/var/log/app.log"), Level: INFO, Below: ERROR}},
+		},
+		{
+			name:   "Does not parse an empty level range",
+			output: "stdout?level=error&below=error",
+			err:    errors.New("err_invalid_out{Out=\"stdout?level=error&below=error\", Message='below must be higher than level'}"),
+		},
+		{
+			name:   "Does not parse an invalid below level",
+			output: "stdout?below=verbose",
+			err:    errors.New("err_invalid_out{Out=\"stdout?below=verbose\", Message='invalid below'}"),
+		},
+		{
+			name:     "Parses a rotating file",
+			output:   "file:///var/log/app.log?maxsize=100MB&maxage=7d&maxbackups=10&compress=gzip&rotate=daily",
+			expected: Out("file:///var/log/app.log?compress=gzip&maxage=7d&maxbackups=10&maxsize=100MB&rotate=daily"),
+			destinations: []Destination{
+				{
+					Out: Out("file:///var/log/app.log"),
+					Rotation: Rotation{
+						MaxSize:     100 << 20,
+						MaxAge:      7 * 24 * time.Hour,
+						MaxBackups:  10,
+						Compression: GzipCompression,
+						Schedule:    DailyRotation,
+					},
+				},
+			},
+		},
+		{
+			name:   "Does not parse a rotating standard stream",
+			output: "stdout?maxsize=1MB",
+			err:    errors.New("err_invalid_out{Out=\"stdout?maxsize=1MB\", Message='rotation needs a file'}"),
+		},
+		{
+			name:   "Does not parse an invalid max size",
+			output: "app.log?maxsize=big",
+			err:    errors.New("err_invalid_out{Out=\"app.log?maxsize=big\", Message='invalid maxsize'}"),
+		},
+		{
+			name:   "Does not parse an invalid max age",
+			output: "app.log?maxage=week",
+			err:    errors.New("err_invalid_out{Out=\"app.log?maxage=week\", Message='invalid maxage'}"),
+		},
+		{
+			name:   "Does not parse invalid max backups",
+			output: "app.log?maxbackups=-1",
+			err:    errors.New("err_invalid_out{Out=\"app.log?maxbackups=-1\", Message='invalid maxbackups'}"),
+		},
+		{
+			name:   "Does not parse an invalid compression",
+			output: "app.log?compress=zip",
+			err:    errors.New("err_invalid_out{Out=\"app.log?compress=zip\", Message='invalid compress'}"),
+		},
+		{
+			name:   "Does not parse an invalid schedule",
+			output: "app.log?rotate=hourly",
+			err:    errors.New("err_invalid_out{Out=\"app.log?rotate=hourly\", Message='invalid rotate'}"),
+		},
+		{
+			name:   "Does not parse a blank destination",
+			output: "stdout,,/var/log/app.log",
+			err:    errors.New("err_invalid_out{Out=\"\"}"),
+		},
+		{
+			name:   "Does not parse an unsupported scheme destination",
+			output: "stdout,http://localhost/logs?level=info",
+			err:    errors.New("err_invalid_out{Out=\"http://localhost/logs\", Message='unsupported scheme'}"),
+		},
+		{
+			name:   "Does not parse an invalid level",
+			output: "stdout?level=verbose",
+			err:    errors.New("err_invalid_out{Out=\"stdout?level=verbose\", Message='invalid level'}"),
+		},
+		{
+			name:   "Does not parse an empty level",
+			output: "stdout?level=",
+			err:    errors.New("err_invalid_out{Out=\"stdout?level=\", Message='empty level'}"),
+		},
+		{
+			name:   "Does not parse an invalid encoding",
+			output: "stdout?encoding=xml",
+			err:    errors.New("err_invalid_out{Out=\"stdout?encoding=xml\", Message='invalid encoding'}"),
+		},
+		{
+			name:   "Does not parse an unknown parameter",
+			output: "stdout?level=info&color=true",
+			err:    errors.New("err_invalid_out{Out=\"stdout?level=info&color=true\", Message='unknown parameter color'}"),
+		},
+		{
+			name:   "Does not parse invalid parameters",
+			output: "stdout?level=%zz",
+			err:    errors.New("err_invalid_out{Out=\"stdout?level=%zz\", Message='invalid parameters'}"),
+		},
+	}
+
+	for index, scenario := range scenarios {
+		test.Run(
+			fmt.Sprintf("[%d]-%s", index, scenario.name),
+			func(t *testing.T) {
+				var out Out
+				err := out.Set(scenario.output)
+				assert.Equal(t, scenario.err, err, "Out.Set error")
+				assert.Exactly(t, scenario.expected, out, "out instance")
+				if scenario.err != nil {
+					return
+				}
+				destinations, err := out.Destinations()
+				assert.NoError(t, err, "destinations error")
+				assert.Equal(t, scenario.destinations, destinations, "destinations")
+				assert.Equal(t, out, NewOut(destinations...), "joined destinations")
+			},
+		)
+	}
+}
+
+func TestOutDestinationsUnparsed(t *testing.T) {
+	destinations, err := Out("").Destinations()
+	assert.NoError(t, err, "empty out")
+	assert.Equal(t, []Destination{{}}, destinations, "empty out destinations")
+
+	destinations, err = Out("stdout?level=invalid").Destinations()
+	assert.EqualError(t, err, "err_invalid_out{Out=\"stdout?level=invalid\", Message='invalid level'}", "invalid out")
+	assert.Nil(t, destinations, "invalid out destinations")
+}
+
+type testOpenDestination struct {
+	name        string
+	destination func(dir string) Destination
+	check       func(*testing.T, Output)
+	err         string
+}
+
+func TestOpenDestination(test *testing.T) {
+	scenarios := []testOpenDestination{
+		{
+			name:        "Opens the standard output without closing it",
+			destination: func(string) Destination { return Destination{Out: STDOUT} },
+			check: func(t *testing.T, output Output) {
+				assert.Equal(t, stdOutput{Writer: os.Stdout}, output, "stdout output")
+				assert.False(t, IsStderr(output), "stdout marked output")
+			},
+		},
+		{
+			name:        "Opens the standard error without closing it",
+			destination: func(string) Destination { return Destination{Out: STDERR} },
+			check: func(t *testing.T, output Output) {
+				assert.Equal(t, stdOutput{Writer: os.Stderr, stderr: true}, output, "stderr output")
+				assert.True(t, IsStderr(output), "stderr marked output")
+			},
+		},
+		{
+			name: "Opens a reopenable file",
+			destination: func(dir string) Destination {
+				return Destination{Out: Out("file://" + filepath.Join(dir, "app.log"))}
+			},
+			check: func(t *testing.T, output Output) {
+				assert.IsType(t, new(ReopenableFile), output, "file output")
+			},
+		},
+		{
+			name: "Opens a rotating file",
+			destination: func(dir string) Destination {
+				return Destination{Out: Out(filepath.Join(dir, "app.log")), Rotation: Rotation{MaxSize: 1024}}
+			},
+			check: func(t *testing.T, output Output) {
+				assert.IsType(t, new(RotatingFile), output, "file output")
+			},
+		},
+		{
+			name:        "Does not open an unsupported scheme",
+			destination: func(string) Destination { return Destination{Out: Out("tcp://localhost:514")} },
+			err:         `err_invalid_out{Out="tcp://localhost:514", Message='unsupported scheme'}`,
+		},
+		{
+			name:        "Does not open a file in a missing directory",
+			destination: func(string) Destination { return Destination{Out: Out("/invalid/path/app.log")} },
+			err:         "open /invalid/path/app.log: no such file or directory",
+		},
+	}
+
+	for index, scenario := range scenarios {
+		test.Run(
+			fmt.Sprintf("[%d]-%s", index, scenario.name),
+			func(t *testing.T) {
+				defer isolateReopeners()()
+				dir, err := ioutil.TempDir("", "l")
+				assert.NoError(t, err, "temp dir")
+				defer os.RemoveAll(dir)
+
+				output, err := OpenDestination(scenario.destination(dir))
+				if scenario.err != "" {
+					assert.EqualError(t, err, scenario.err, "open error")
+					assert.Nil(t, output, "output")
+					return
+				}
+				assert.NoError(t, err, "open error")
+				scenario.check(t, output)
+				assert.NoError(t, output.Sync(), "sync output")
+				assert.NoError(t, output.Close(), "close output")
+			},
+		)
+	}
+}
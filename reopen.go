@@ -0,0 +1,155 @@
+package l
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+)
+
+// reopener is a file opened by this package which can be opened again after an external logrotate moved it
+type reopener interface {
+	Reopen() error
+}
+
+var reopeners = struct {
+	sync.Mutex
+	files map[reopener]struct{}
+}{files: make(map[reopener]struct{})}
+
+func registerReopener(file reopener) {
+	reopeners.Lock()
+	reopeners.files[file] = struct{}{}
+	reopeners.Unlock()
+}
+
+func unregisterReopener(file reopener) {
+	reopeners.Lock()
+	delete(reopeners.files, file)
+	reopeners.Unlock()
+}
+
+// Reopen opens again every file output which is not closed, it returns the errors of all the files
+func Reopen() error {
+	reopeners.Lock()
+	files := make([]reopener, 0, len(reopeners.files))
+	for file := range reopeners.files {
+		files = append(files, file)
+	}
+	reopeners.Unlock()
+
+	var errs []error
+	for _, file := range files {
+		if err := file.Reopen(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// ReopenOnSignal calls Reopen when the process receives one of the signals, like syscall.SIGHUP from logrotate,
+// the returned function stops it. A Reopen error is reported to the ErrorHandler
+func ReopenOnSignal(signals ...os.Signal) func() {
+	var (
+		received = make(chan os.Signal, 1)
+		done     = make(chan struct{})
+		stopped  sync.WaitGroup
+		once     sync.Once
+	)
+	signal.Notify(received, signals...)
+	stopped.Add(1)
+	go func() {
+		defer stopped.Done()
+		for {
+			select {
+			case <-received:
+				if err := Reopen(); err != nil {
+					HandleError(fmt.Errorf("err_reopen{Message='%w'}", err))
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+	return func() {
+		once.Do(func() {
+			signal.Stop(received)
+			close(done)
+			stopped.Wait()
+		})
+	}
+}
+
+// ReopenableFile is a file writer which can swap its handle for a new file at the same path without losing entries
+type ReopenableFile struct {
+	mutex  sync.Mutex
+	path   string
+	file   *os.File
+	closed bool
+}
+
+// OpenReopenableFile opens the file for append creating it when it does not exist, Reopen and ReopenOnSignal open it again
+func OpenReopenableFile(path string) (*ReopenableFile, error) {
+	osFile, err := openAppendFile(path)
+	if err != nil {
+		return nil, err
+	}
+	file := &ReopenableFile{path: path, file: osFile}
+	registerReopener(file)
+	return file, nil
+}
+
+func openAppendFile(path string) (*os.File, error) {
+	return os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+}
+
+// Write writes the entry with one write call, an entry is never split between the old and the new file
+func (file *ReopenableFile) Write(entry []byte) (int, error) {
+	file.mutex.Lock()
+	defer file.mutex.Unlock()
+	if file.closed {
+		return 0, os.ErrClosed
+	}
+	return file.file.Write(entry)
+}
+
+// Reopen opens the path again and swaps the handles, the writes wait the swap and the previous file is closed after it
+func (file *ReopenableFile) Reopen() error {
+	osFile, err := openAppendFile(file.path)
+	if err != nil {
+		return err
+	}
+	file.mutex.Lock()
+	if file.closed {
+		file.mutex.Unlock()
+		_ = osFile.Close()
+		return os.ErrClosed
+	}
+	previous := file.file
+	file.file = osFile
+	file.mutex.Unlock()
+	return previous.Close()
+}
+
+// Sync commits the written entries to the disk
+func (file *ReopenableFile) Sync() error {
+	file.mutex.Lock()
+	defer file.mutex.Unlock()
+	if file.closed {
+		return nil
+	}
+	return file.file.Sync()
+}
+
+// Close closes the file, it is not opened again after it
+func (file *ReopenableFile) Close() error {
+	file.mutex.Lock()
+	defer file.mutex.Unlock()
+	if file.closed {
+		return nil
+	}
+	file.closed = true
+	unregisterReopener(file)
+	return file.file.Close()
+}
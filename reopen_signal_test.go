@@ -0,0 +1,44 @@
+//go:build !windows
+
+package l
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReopenOnSignal(t *testing.T) {
+	defer isolateReopeners()()
+
+	dir, err := ioutil.TempDir("", "reopen")
+	assert.NoError(t, err, "temp dir")
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "app.log")
+	file, err := OpenReopenableFile(path)
+	assert.NoError(t, err, "open file")
+	defer file.Close()
+
+	stop := ReopenOnSignal(syscall.SIGHUP)
+	defer stop()
+
+	assert.NoError(t, os.Rename(path, path+".1"), "move file")
+	assert.NoError(t, syscall.Kill(syscall.Getpid(), syscall.SIGHUP), "send signal")
+
+	// the file is created again by the signal handler
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		if _, err = os.Stat(path); err == nil {
+			break
+		}
+	}
+	assert.NoError(t, err, "reopened file")
+
+	stop()
+	stop()
+}
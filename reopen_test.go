@@ -0,0 +1,159 @@
+package l
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// isolateReopeners replaces the registry for a test, the files opened by the other tests are not reopened
+func isolateReopeners() func() {
+	reopeners.Lock()
+	files := reopeners.files
+	reopeners.files = make(map[reopener]struct{})
+	reopeners.Unlock()
+	return func() {
+		reopeners.Lock()
+		reopeners.files = files
+		reopeners.Unlock()
+	}
+}
+
+// readLines returns the lines of every file in the directory
+func readLines(t *testing.T, dir string) []string {
+	infos, err := ioutil.ReadDir(dir)
+	assert.NoError(t, err, "read dir")
+	var lines []string
+	for _, info := range infos {
+		data, err := ioutil.ReadFile(filepath.Join(dir, info.Name()))
+		assert.NoError(t, err, "read %s", info.Name())
+		if trimmed := strings.TrimSuffix(string(data), "\n"); trimmed != "" {
+			lines = append(lines, strings.Split(trimmed, "\n")...)
+		}
+	}
+	return lines
+}
+
+func TestReopenableFile(t *testing.T) {
+	defer isolateReopeners()()
+
+	dir, err := ioutil.TempDir("", "reopen")
+	assert.NoError(t, err, "temp dir")
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "app.log")
+	file, err := OpenReopenableFile(path)
+	assert.NoError(t, err, "open file")
+
+	var (
+		writers    sync.WaitGroup
+		goroutines = 8
+		entries    = 500
+	)
+	for goroutine := 0; goroutine < goroutines; goroutine++ {
+		writers.Add(1)
+		go func(goroutine int) {
+			defer writers.Done()
+			for index := 0; index < entries; index++ {
+				_, err := file.Write([]byte(fmt.Sprintf("goroutine=%d entry=%d %s\n", goroutine, index, strings.Repeat("x", index%64))))
+				assert.NoError(t, err, "write entry")
+			}
+		}(goroutine)
+	}
+	// moves the file like logrotate in create mode while the goroutines write
+	for rotation := 0; rotation < 20; rotation++ {
+		assert.NoError(t, os.Rename(path, fmt.Sprintf("%s.%d", path, rotation)), "move file")
+		assert.NoError(t, Reopen(), "reopen files")
+	}
+	writers.Wait()
+	assert.NoError(t, file.Sync(), "sync file")
+	assert.NoError(t, file.Close(), "close file")
+
+	var (
+		lines   = readLines(t, dir)
+		pattern = regexp.MustCompile(`^goroutine=\d+ entry=\d+ x*$`)
+		unique  = make(map[string]struct{}, len(lines))
+	)
+	assert.Len(t, lines, goroutines*entries, "lines")
+	for _, line := range lines {
+		assert.Regexp(t, pattern, line, "line")
+		unique[line] = struct{}{}
+	}
+	assert.Len(t, unique, goroutines*entries, "unique lines")
+}
+
+func TestReopenableFileClose(t *testing.T) {
+	dir, err := ioutil.TempDir("", "reopen")
+	assert.NoError(t, err, "temp dir")
+	defer os.RemoveAll(dir)
+
+	file, err := OpenReopenableFile(filepath.Join(dir, "app.log"))
+	assert.NoError(t, err, "open file")
+	reopeners.Lock()
+	_, registered := reopeners.files[file]
+	reopeners.Unlock()
+	assert.True(t, registered, "registered file")
+
+	assert.NoError(t, file.Close(), "close file")
+	assert.NoError(t, file.Close(), "close closed file")
+	reopeners.Lock()
+	_, registered = reopeners.files[file]
+	reopeners.Unlock()
+	assert.False(t, registered, "unregistered file")
+
+	_, err = file.Write([]byte("entry\n"))
+	assert.Equal(t, os.ErrClosed, err, "write closed file")
+	assert.Equal(t, os.ErrClosed, file.Reopen(), "reopen closed file")
+	assert.NoError(t, file.Sync(), "sync closed file")
+
+	_, err = OpenReopenableFile(filepath.Join(dir, "missing", "app.log"))
+	assert.Error(t, err, "open missing dir")
+}
+
+func TestReopenError(t *testing.T) {
+	defer isolateReopeners()()
+
+	dir, err := ioutil.TempDir("", "reopen")
+	assert.NoError(t, err, "temp dir")
+	defer os.RemoveAll(dir)
+
+	logsDir := filepath.Join(dir, "logs")
+	assert.NoError(t, os.Mkdir(logsDir, 0755), "logs dir")
+	file, err := OpenReopenableFile(filepath.Join(logsDir, "app.log"))
+	assert.NoError(t, err, "open file")
+	defer file.Close()
+
+	assert.NoError(t, os.RemoveAll(logsDir), "remove logs dir")
+	assert.Error(t, Reopen(), "reopen error")
+	_, err = file.Write([]byte("entry\n"))
+	assert.NoError(t, err, "write to the previous file")
+}
+
+func TestRotatingFileReopen(t *testing.T) {
+	defer isolateReopeners()()
+
+	dir, err := ioutil.TempDir("", "reopen")
+	assert.NoError(t, err, "temp dir")
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "app.log")
+	file, err := OpenRotatingFile(path, Rotation{MaxSize: 1 << 20})
+	assert.NoError(t, err, "open file")
+	_, err = file.Write([]byte("entry1\n"))
+	assert.NoError(t, err, "write entry")
+	assert.NoError(t, os.Rename(path, path+".1"), "move file")
+	assert.NoError(t, Reopen(), "reopen files")
+	_, err = file.Write([]byte("entry2\n"))
+	assert.NoError(t, err, "write entry")
+	assert.NoError(t, file.Close(), "close file")
+	assert.Equal(t, os.ErrClosed, file.Reopen(), "reopen closed file")
+
+	assert.Equal(t, map[string]string{"app.log": "entry2\n", "app.log.1": "entry1\n"}, rotatedFiles(t, dir), "files")
+}
@@ -0,0 +1,407 @@
+package l
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// GzipCompression compresses the rotated files with gzip
+	GzipCompression = "gzip"
+
+	// DailyRotation rotates the file at midnight
+	DailyRotation = "daily"
+
+	// rotationTimeLayout is the UTC time of the rotation in the backup file names, like app-2019-10-01T12-30-15.123.log
+	rotationTimeLayout = "2006-01-02T15-04-05.000"
+	compressedExt      = ".gz"
+)
+
+// Rotation configures the rotation of a file destination, the zero value does not rotate the file
+type Rotation struct {
+	// MaxSize is the size in bytes from which the file is rotated, zero does not rotate by size
+	MaxSize int64
+	// MaxAge is the age from which the rotated files are removed, zero keeps them
+	MaxAge time.Duration
+	// MaxBackups is the number of rotated files kept, zero keeps them
+	MaxBackups int
+	// Compression is the compression of the rotated files, only GzipCompression is supported and empty does not compress
+	Compression string
+	// Schedule rotates the file on a schedule, only DailyRotation is supported and empty does not rotate by time
+	Schedule string
+}
+
+// Enabled reports whether the rotation has any option
+func (rotation Rotation) Enabled() bool {
+	return rotation != Rotation{}
+}
+
+// ParseSize parses a size in bytes with an optional KB, MB or GB suffix of 1024 multiples, like 100MB
+func ParseSize(value string) (int64, error) {
+	var (
+		number     = strings.ToUpper(strings.TrimSpace(value))
+		multiplier = int64(1)
+	)
+	for _, unit := range []struct {
+		suffix     string
+		multiplier int64
+	}{{"KB", 1 << 10}, {"MB", 1 << 20}, {"GB", 1 << 30}, {"B", 1}} {
+		if strings.HasSuffix(number, unit.suffix) {
+			number = strings.TrimSuffix(number, unit.suffix)
+			multiplier = unit.multiplier
+			break
+		}
+	}
+	size, err := strconv.ParseInt(number, 10, 64)
+	if err != nil || size < 0 {
+		return 0, fmt.Errorf("err_invalid_size{Size=%q}", value)
+	}
+	return size * multiplier, nil
+}
+
+func formatSize(size int64) string {
+	for _, unit := range []struct {
+		suffix     string
+		multiplier int64
+	}{{"GB", 1 << 30}, {"MB", 1 << 20}, {"KB", 1 << 10}} {
+		if size >= unit.multiplier && size%unit.multiplier == 0 {
+			return strconv.FormatInt(size/unit.multiplier, 10) + unit.suffix
+		}
+	}
+	return strconv.FormatInt(size, 10)
+}
+
+// ParseAge parses a duration which also accepts a number of days with the d suffix, like 7d
+func ParseAge(value string) (time.Duration, error) {
+	if days := strings.TrimSuffix(value, "d"); days != value {
+		number, err := strconv.Atoi(days)
+		if err != nil || number < 0 {
+			return 0, fmt.Errorf("err_invalid_age{Age=%q}", value)
+		}
+		return time.Duration(number) * 24 * time.Hour, nil
+	}
+	age, err := time.ParseDuration(value)
+	if err != nil || age < 0 {
+		return 0, fmt.Errorf("err_invalid_age{Age=%q}", value)
+	}
+	return age, nil
+}
+
+func formatAge(age time.Duration) string {
+	day := 24 * time.Hour
+	if age >= day && age%day == 0 {
+		return strconv.FormatInt(int64(age/day), 10) + "d"
+	}
+	return age.String()
+}
+
+// RotatingFile is a file writer which rotates the file by size and or daily,
+// the rotated files are compressed and removed by the retention options in background
+type RotatingFile struct {
+	mutex        sync.Mutex
+	path         string
+	rotation     Rotation
+	now          func() time.Time
+	file         *os.File
+	size         int64
+	nextRotation time.Time
+	closed       bool
+	millMutex    sync.Mutex
+	milling      sync.WaitGroup
+}
+
+// OpenRotatingFile opens the file for append creating it and its directory when they do not exist,
+// Reopen and ReopenOnSignal open it again
+func OpenRotatingFile(path string, rotation Rotation) (*RotatingFile, error) {
+	file, err := openRotatingFile(path, rotation, time.Now)
+	if err != nil {
+		return nil, err
+	}
+	registerReopener(file)
+	return file, nil
+}
+
+func openRotatingFile(path string, rotation Rotation, now func() time.Time) (*RotatingFile, error) {
+	if rotation.Compression != "" && rotation.Compression != GzipCompression {
+		return nil, fmt.Errorf("err_invalid_rotation{Compression=%q}", rotation.Compression)
+	}
+	if rotation.Schedule != "" && rotation.Schedule != DailyRotation {
+		return nil, fmt.Errorf("err_invalid_rotation{Schedule=%q}", rotation.Schedule)
+	}
+	file := &RotatingFile{
+		path:     path,
+		rotation: rotation,
+		now:      now,
+	}
+	if err := file.open(); err != nil {
+		return nil, err
+	}
+	return file, nil
+}
+
+func (file *RotatingFile) open() error {
+	if err := os.MkdirAll(filepath.Dir(file.path), 0755); err != nil {
+		return err
+	}
+	osFile, err := os.OpenFile(file.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return err
+	}
+	info, err := osFile.Stat()
+	if err != nil {
+		_ = osFile.Close()
+		return err
+	}
+	file.file = osFile
+	file.size = info.Size()
+	// a file written before a restart is rotated on the first write after the midnight following its last write
+	openedAt := file.now()
+	if file.size > 0 && info.ModTime().Before(openedAt) {
+		openedAt = info.ModTime()
+	}
+	file.nextRotation = nextMidnight(openedAt)
+	return nil
+}
+
+func nextMidnight(value time.Time) time.Time {
+	year, month, day := value.Date()
+	return time.Date(year, month, day+1, 0, 0, 0, 0, value.Location())
+}
+
+// Write writes the entry to the file rotating it before when the entry exceeds the max size or the schedule is due
+func (file *RotatingFile) Write(entry []byte) (int, error) {
+	file.mutex.Lock()
+	defer file.mutex.Unlock()
+
+	if file.closed {
+		return 0, os.ErrClosed
+	}
+	// the file is nil when a rotation failed to open the new file, so it is opened again
+	if file.file == nil {
+		if err := file.open(); err != nil {
+			return 0, err
+		}
+	}
+	if file.rotationDue(int64(len(entry))) {
+		if err := file.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	written, err := file.file.Write(entry)
+	file.size += int64(written)
+	return written, err
+}
+
+func (file *RotatingFile) rotationDue(entrySize int64) bool {
+	if file.size == 0 {
+		return false
+	}
+	if file.rotation.MaxSize > 0 && file.size+entrySize > file.rotation.MaxSize {
+		return true
+	}
+	return file.rotation.Schedule == DailyRotation && !file.now().Before(file.nextRotation)
+}
+
+// Rotate closes the file, renames it with the rotation time and opens a new file
+func (file *RotatingFile) Rotate() error {
+	file.mutex.Lock()
+	defer file.mutex.Unlock()
+	if file.closed {
+		return os.ErrClosed
+	}
+	return file.rotate()
+}
+
+func (file *RotatingFile) rotate() error {
+	if file.file != nil {
+		if err := file.file.Close(); err != nil {
+			return err
+		}
+		file.file = nil
+	}
+	if err := os.Rename(file.path, file.backupPath(file.now())); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if err := file.open(); err != nil {
+		return err
+	}
+	file.milling.Add(1)
+	go file.mill()
+	return nil
+}
+
+// Reopen opens the path again, like after an external logrotate moved the file
+func (file *RotatingFile) Reopen() error {
+	file.mutex.Lock()
+	defer file.mutex.Unlock()
+	if file.closed {
+		return os.ErrClosed
+	}
+	if file.file != nil {
+		if err := file.file.Close(); err != nil {
+			return err
+		}
+		file.file = nil
+	}
+	return file.open()
+}
+
+// backupPath returns an unused name with the rotation time between the file name and its extension
+func (file *RotatingFile) backupPath(rotatedAt time.Time) string {
+	var (
+		ext    = filepath.Ext(file.path)
+		prefix = strings.TrimSuffix(file.path, ext) + "-"
+	)
+	for {
+		path := prefix + rotatedAt.UTC().Format(rotationTimeLayout) + ext
+		_, errPath := os.Stat(path)
+		_, errCompressed := os.Stat(path + compressedExt)
+		if os.IsNotExist(errPath) && os.IsNotExist(errCompressed) {
+			return path
+		}
+		rotatedAt = rotatedAt.Add(time.Millisecond)
+	}
+}
+
+type backup struct {
+	path      string
+	rotatedAt time.Time
+}
+
+// backups returns the rotated files of the file from the newest to the oldest
+func (file *RotatingFile) backups() ([]backup, error) {
+	var (
+		dir    = filepath.Dir(file.path)
+		ext    = filepath.Ext(file.path)
+		prefix = strings.TrimSuffix(filepath.Base(file.path), ext) + "-"
+	)
+	infos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var backups []backup
+	for _, info := range infos {
+		name := info.Name()
+		if info.IsDir() || !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		timestamp := strings.TrimSuffix(strings.TrimSuffix(strings.TrimPrefix(name, prefix), compressedExt), ext)
+		rotatedAt, err := time.Parse(rotationTimeLayout, timestamp)
+		if err != nil {
+			continue
+		}
+		backups = append(backups, backup{path: filepath.Join(dir, name), rotatedAt: rotatedAt})
+	}
+	sort.Slice(backups, func(i, j int) bool {
+		return backups[i].rotatedAt.After(backups[j].rotatedAt)
+	})
+	return backups, nil
+}
+
+// mill removes the rotated files beyond the retention and compresses the remaining ones,
+// it runs in background after each rotation and reports its failures to the ErrorHandler
+func (file *RotatingFile) mill() {
+	defer file.milling.Done()
+	file.millMutex.Lock()
+	defer file.millMutex.Unlock()
+
+	backups, err := file.backups()
+	if err != nil {
+		HandleError(fmt.Errorf("err_rotation{Path=%q, Message='%w'}", file.path, err))
+		return
+	}
+	var (
+		now    = file.now()
+		remove []backup
+		keep   []backup
+	)
+	for index, backup := range backups {
+		expired := file.rotation.MaxAge > 0 && now.Sub(backup.rotatedAt) > file.rotation.MaxAge
+		exceeded := file.rotation.MaxBackups > 0 && index >= file.rotation.MaxBackups
+		if expired || exceeded {
+			remove = append(remove, backup)
+		} else {
+			keep = append(keep, backup)
+		}
+	}
+	for _, backup := range remove {
+		if err := os.Remove(backup.path); err != nil {
+			HandleError(fmt.Errorf("err_rotation{Path=%q, Message='%w'}", backup.path, err))
+		}
+	}
+	if file.rotation.Compression != GzipCompression {
+		return
+	}
+	for _, backup := range keep {
+		if strings.HasSuffix(backup.path, compressedExt) {
+			continue
+		}
+		if err := compressFile(backup.path); err != nil {
+			HandleError(fmt.Errorf("err_rotation{Path=%q, Message='%w'}", backup.path, err))
+		}
+	}
+}
+
+// compressFile writes the gzip file and removes the source only after the compressed file is complete
+func compressFile(path string) error {
+	source, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer source.Close()
+
+	temp := path + compressedExt + ".tmp"
+	target, err := os.OpenFile(temp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	writer := gzip.NewWriter(target)
+	if _, err = io.Copy(writer, source); err == nil {
+		err = writer.Close()
+	}
+	if errClose := target.Close(); err == nil {
+		err = errClose
+	}
+	if err == nil {
+		err = os.Rename(temp, path+compressedExt)
+	}
+	if err != nil {
+		_ = os.Remove(temp)
+		return err
+	}
+	return os.Remove(path)
+}
+
+// Sync commits the written entries to the disk
+func (file *RotatingFile) Sync() error {
+	file.mutex.Lock()
+	defer file.mutex.Unlock()
+	if file.file == nil {
+		return nil
+	}
+	return file.file.Sync()
+}
+
+// Close closes the file and waits the background compression and retention
+func (file *RotatingFile) Close() error {
+	file.mutex.Lock()
+	var err error
+	file.closed = true
+	unregisterReopener(file)
+	if file.file != nil {
+		err = file.file.Close()
+		file.file = nil
+	}
+	file.mutex.Unlock()
+	file.milling.Wait()
+	return err
+}
@@ -0,0 +1,277 @@
+package l
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testRotationClock is a fake clock safe for the background retention
+type testRotationClock struct {
+	mutex sync.Mutex
+	time  time.Time
+}
+
+func newTestRotationClock() *testRotationClock {
+	return &testRotationClock{time: time.Date(2019, 10, 1, 12, 30, 15, 0, time.UTC)}
+}
+
+func (clock *testRotationClock) now() time.Time {
+	clock.mutex.Lock()
+	defer clock.mutex.Unlock()
+	return clock.time
+}
+
+func (clock *testRotationClock) add(duration time.Duration) {
+	clock.mutex.Lock()
+	clock.time = clock.time.Add(duration)
+	clock.mutex.Unlock()
+}
+
+// rotatedFiles returns the names and the contents of the files in the directory, gzip files are decompressed
+func rotatedFiles(t *testing.T, dir string) map[string]string {
+	infos, err := ioutil.ReadDir(dir)
+	assert.NoError(t, err, "read dir")
+	files := make(map[string]string, len(infos))
+	for _, info := range infos {
+		path := filepath.Join(dir, info.Name())
+		data, err := ioutil.ReadFile(path)
+		assert.NoError(t, err, "read %s", path)
+		if strings.HasSuffix(path, ".gz") {
+			reader, err := gzip.NewReader(strings.NewReader(string(data)))
+			assert.NoError(t, err, "gzip %s", path)
+			data, err = ioutil.ReadAll(reader)
+			assert.NoError(t, err, "gunzip %s", path)
+		}
+		files[info.Name()] = string(data)
+	}
+	return files
+}
+
+type testRotatingFile struct {
+	name     string
+	rotation Rotation
+	write    func(*RotatingFile, *testRotationClock)
+	expected map[string]string
+}
+
+func TestRotatingFile(test *testing.T) {
+	scenarios := []testRotatingFile{
+		{
+			name:     "Does not rotate without options",
+			rotation: Rotation{},
+			write: func(file *RotatingFile, clock *testRotationClock) {
+				file.Write([]byte("entry1\n"))
+				clock.add(48 * time.Hour)
+				file.Write([]byte("entry2\n"))
+			},
+			expected: map[string]string{"app.log": "entry1\nentry2\n"},
+		},
+		{
+			name:     "Rotates by size",
+			rotation: Rotation{MaxSize: 14},
+			write: func(file *RotatingFile, clock *testRotationClock) {
+				file.Write([]byte("entry1\n"))
+				file.Write([]byte("entry2\n"))
+				clock.add(time.Second)
+				file.Write([]byte("entry3\n"))
+				clock.add(time.Second)
+				file.Write([]byte("a very long entry4\n"))
+			},
+			expected: map[string]string{
+				"app-2019-10-01T12-30-16.000.log": "entry1\nentry2\n",
+				"app-2019-10-01T12-30-17.000.log": "entry3\n",
+				"app.log":                         "a very long entry4\n",
+			},
+		},
+		{
+			name:     "Rotates daily",
+			rotation: Rotation{Schedule: DailyRotation},
+			write: func(file *RotatingFile, clock *testRotationClock) {
+				file.Write([]byte("entry1\n"))
+				clock.add(11 * time.Hour)
+				file.Write([]byte("entry2\n"))
+				clock.add(time.Hour)
+				file.Write([]byte("entry3\n"))
+				clock.add(72 * time.Hour)
+				file.Write([]byte("entry4\n"))
+			},
+			expected: map[string]string{
+				"app-2019-10-02T00-30-15.000.log": "entry1\nentry2\n",
+				"app-2019-10-05T00-30-15.000.log": "entry3\n",
+				"app.log":                         "entry4\n",
+			},
+		},
+		{
+			name:     "Keeps the max backups",
+			rotation: Rotation{MaxSize: 1, MaxBackups: 2},
+			write: func(file *RotatingFile, clock *testRotationClock) {
+				for index := 1; index <= 5; index++ {
+					file.Write([]byte(fmt.Sprintf("entry%d\n", index)))
+					clock.add(time.Second)
+				}
+			},
+			expected: map[string]string{
+				"app-2019-10-01T12-30-18.000.log": "entry3\n",
+				"app-2019-10-01T12-30-19.000.log": "entry4\n",
+				"app.log":                         "entry5\n",
+			},
+		},
+		{
+			name:     "Removes the backups older than the max age",
+			rotation: Rotation{MaxSize: 1, MaxAge: 48 * time.Hour},
+			write: func(file *RotatingFile, clock *testRotationClock) {
+				file.Write([]byte("entry1\n"))
+				clock.add(24 * time.Hour)
+				file.Write([]byte("entry2\n"))
+				clock.add(24 * time.Hour)
+				file.Write([]byte("entry3\n"))
+				clock.add(25 * time.Hour)
+				file.Write([]byte("entry4\n"))
+			},
+			expected: map[string]string{
+				"app-2019-10-03T12-30-15.000.log": "entry2\n",
+				"app-2019-10-04T13-30-15.000.log": "entry3\n",
+				"app.log":                         "entry4\n",
+			},
+		},
+		{
+			name:     "Compresses the backups",
+			rotation: Rotation{MaxSize: 1, Compression: GzipCompression},
+			write: func(file *RotatingFile, clock *testRotationClock) {
+				file.Write([]byte("entry1\n"))
+				clock.add(time.Second)
+				file.Write([]byte("entry2\n"))
+				file.milling.Wait()
+				file.Write([]byte("entry3\n"))
+			},
+			expected: map[string]string{
+				"app-2019-10-01T12-30-16.000.log.gz": "entry1\n",
+				"app-2019-10-01T12-30-16.001.log.gz": "entry2\n",
+				"app.log":                            "entry3\n",
+			},
+		},
+	}
+
+	for index, scenario := range scenarios {
+		test.Run(
+			fmt.Sprintf("[%d]-%s", index, scenario.name),
+			func(t *testing.T) {
+				dir, err := ioutil.TempDir("", "rotate")
+				assert.NoError(t, err, "temp dir")
+				defer os.RemoveAll(dir)
+
+				clock := newTestRotationClock()
+				file, err := openRotatingFile(filepath.Join(dir, "app.log"), scenario.rotation, clock.now)
+				assert.NoError(t, err, "open file")
+				scenario.write(file, clock)
+				assert.NoError(t, file.Close(), "close file")
+				assert.Equal(t, scenario.expected, rotatedFiles(t, dir), "files")
+			},
+		)
+	}
+}
+
+func TestRotatingFileRestart(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rotate")
+	assert.NoError(t, err, "temp dir")
+	defer os.RemoveAll(dir)
+
+	var (
+		path  = filepath.Join(dir, "logs", "app.log")
+		clock = newTestRotationClock()
+	)
+	file, err := openRotatingFile(path, Rotation{Schedule: DailyRotation}, clock.now)
+	assert.NoError(t, err, "open file")
+	_, err = file.Write([]byte("entry1\n"))
+	assert.NoError(t, err, "write entry")
+	assert.NoError(t, file.Close(), "close file")
+	_, err = file.Write([]byte("closed\n"))
+	assert.Equal(t, os.ErrClosed, err, "write closed file")
+	assert.Equal(t, os.ErrClosed, file.Rotate(), "rotate closed file")
+
+	lastWrite := time.Date(2019, 9, 30, 22, 0, 0, 0, time.Local)
+	assert.NoError(t, os.Chtimes(path, lastWrite, lastWrite), "file time")
+	file, err = openRotatingFile(path, Rotation{Schedule: DailyRotation}, clock.now)
+	assert.NoError(t, err, "reopen file")
+	_, err = file.Write([]byte("entry2\n"))
+	assert.NoError(t, err, "write entry")
+	assert.NoError(t, file.Rotate(), "rotate file")
+	assert.NoError(t, file.Close(), "close file")
+
+	files := rotatedFiles(t, filepath.Dir(path))
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	assert.Equal(t, []string{"app-2019-10-01T12-30-15.000.log", "app-2019-10-01T12-30-15.001.log", "app.log"}, names, "files")
+	assert.Equal(t, "entry1\n", files[names[0]], "restart rotated file")
+	assert.Equal(t, "entry2\n", files[names[1]], "rotated file")
+	assert.Equal(t, "", files[names[2]], "current file")
+}
+
+func TestOpenRotatingFileError(t *testing.T) {
+	_, err := OpenRotatingFile("/dev/null/app.log", Rotation{})
+	assert.Error(t, err, "invalid path")
+	_, err = OpenRotatingFile("app.log", Rotation{Compression: "zip"})
+	assert.Equal(t, errors.New("err_invalid_rotation{Compression=\"zip\"}"), err, "invalid compression")
+	_, err = OpenRotatingFile("app.log", Rotation{Schedule: "hourly"})
+	assert.Equal(t, errors.New("err_invalid_rotation{Schedule=\"hourly\"}"), err, "invalid schedule")
+}
+
+type testParseRotation struct {
+	name     string
+	value    string
+	parse    func(string) (interface{}, error)
+	expected interface{}
+	err      error
+}
+
+func TestParseRotation(test *testing.T) {
+	var (
+		parseSize = func(value string) (interface{}, error) { return ParseSize(value) }
+		parseAge  = func(value string) (interface{}, error) { return ParseAge(value) }
+	)
+	scenarios := []testParseRotation{
+		{name: "Parses bytes", value: "512", parse: parseSize, expected: int64(512)},
+		{name: "Parses a bytes suffix", value: "512b", parse: parseSize, expected: int64(512)},
+		{name: "Parses kilobytes", value: "10KB", parse: parseSize, expected: int64(10 << 10)},
+		{name: "Parses megabytes", value: "100MB", parse: parseSize, expected: int64(100 << 20)},
+		{name: "Parses gigabytes", value: "1gb", parse: parseSize, expected: int64(1 << 30)},
+		{name: "Does not parse an invalid size", value: "10TB", parse: parseSize, expected: int64(0), err: errors.New("err_invalid_size{Size=\"10TB\"}")},
+		{name: "Does not parse a negative size", value: "-1", parse: parseSize, expected: int64(0), err: errors.New("err_invalid_size{Size=\"-1\"}")},
+		{name: "Parses days", value: "7d", parse: parseAge, expected: 7 * 24 * time.Hour},
+		{name: "Parses a duration", value: "12h30m", parse: parseAge, expected: 12*time.Hour + 30*time.Minute},
+		{name: "Does not parse invalid days", value: "xd", parse: parseAge, expected: time.Duration(0), err: errors.New("err_invalid_age{Age=\"xd\"}")},
+		{name: "Does not parse a negative duration", value: "-1h", parse: parseAge, expected: time.Duration(0), err: errors.New("err_invalid_age{Age=\"-1h\"}")},
+	}
+
+	for index, scenario := range scenarios {
+		test.Run(
+			fmt.Sprintf("[%d]-%s", index, scenario.name),
+			func(t *testing.T) {
+				value, err := scenario.parse(scenario.value)
+				assert.Equal(t, scenario.err, err, "parse error")
+				assert.Equal(t, scenario.expected, value, "parsed value")
+			},
+		)
+	}
+}
+
+func TestFormatRotation(t *testing.T) {
+	assert.Equal(t, "1GB", formatSize(1<<30), "format gigabytes")
+	assert.Equal(t, "1025", formatSize(1025), "format bytes")
+	assert.Equal(t, "2d", formatAge(48*time.Hour), "format days")
+	assert.Equal(t, "36h0m0s", formatAge(36*time.Hour), "format duration")
+}
@@ -0,0 +1,103 @@
+package l
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// Closer is closed by Shutdown, a Logger and a Driver are Closers
+type Closer interface {
+	Close(context.Context) error
+}
+
+var shutdowns = struct {
+	sync.Mutex
+	closers []Closer
+}{}
+
+// RegisterShutdown adds closers to be closed by Shutdown, like a Logger with an async driver or a file output
+func RegisterShutdown(closers ...Closer) {
+	shutdowns.Lock()
+	defer shutdowns.Unlock()
+	for _, closer := range closers {
+		if closer != nil {
+			shutdowns.closers = append(shutdowns.closers, closer)
+		}
+	}
+}
+
+// Shutdown closes the registered closers from the last to the first one and then LoggerDefault,
+// a closer is closed once and the errors of all of them are returned
+func Shutdown(ctx context.Context) error {
+	shutdowns.Lock()
+	closers := shutdowns.closers
+	shutdowns.closers = nil
+	shutdowns.Unlock()
+
+	var errs []error
+	for index := len(closers) - 1; index >= 0; index-- {
+		if err := closers[index].Close(ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if err := LoggerDefault.Close(ctx); err != nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+// raise delivers the signal again after Shutdown, so the process stops like it would without ShutdownOnSignal
+var raise = func(sig os.Signal) {
+	process, err := os.FindProcess(os.Getpid())
+	if err == nil && process.Signal(sig) == nil {
+		return
+	}
+	exit(1)
+}
+
+// ShutdownOnSignal calls Shutdown when the process receives one of the signals, os.Interrupt and syscall.SIGTERM by default,
+// and then delivers the signal again to stop the process. The timeout bounds Shutdown, zero is DefaultDrainTimeout.
+// A Shutdown error is reported to the ErrorHandler and the returned function stops it.
+func ShutdownOnSignal(timeout time.Duration, signals ...os.Signal) func() {
+	if len(signals) == 0 {
+		signals = []os.Signal{os.Interrupt, syscall.SIGTERM}
+	}
+	if timeout <= 0 {
+		timeout = DefaultDrainTimeout
+	}
+	var (
+		received = make(chan os.Signal, 1)
+		done     = make(chan struct{})
+		stopped  sync.WaitGroup
+		once     sync.Once
+	)
+	signal.Notify(received, signals...)
+	stopped.Add(1)
+	go func() {
+		defer stopped.Done()
+		select {
+		case sig := <-received:
+			signal.Stop(received)
+			ctx, cancel := context.WithTimeout(context.Background(), timeout)
+			defer cancel()
+			if err := Shutdown(ctx); err != nil {
+				HandleError(fmt.Errorf("err_shutdown{Message='%w'}", err))
+			}
+			raise(sig)
+		case <-done:
+		}
+	}()
+	return func() {
+		once.Do(func() {
+			signal.Stop(received)
+			close(done)
+			stopped.Wait()
+		})
+	}
+}
@@ -0,0 +1,43 @@
+//go:build !windows
+
+package l
+
+import (
+	"os"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestShutdownOnSignal(t *testing.T) {
+	var (
+		closed []string
+		driver = newMockDriver()
+		raised = make(chan os.Signal, 1)
+	)
+	defer isolateShutdown(New(driver))()
+	driver.On("Close", mock.Anything).Return(nil).Once()
+	defaultRaise := raise
+	raise = func(sig os.Signal) { raised <- sig }
+	defer func() { raise = defaultRaise }()
+
+	RegisterShutdown(testCloser{name: "closer", closed: &closed})
+	stop := ShutdownOnSignal(time.Second, syscall.SIGUSR1)
+	defer stop()
+	assert.NoError(t, syscall.Kill(syscall.Getpid(), syscall.SIGUSR1), "send signal")
+
+	select {
+	case sig := <-raised:
+		assert.Equal(t, syscall.SIGUSR1, sig, "raised signal")
+	case <-time.After(5 * time.Second):
+		t.Fatal("signal was not raised after shutdown")
+	}
+	assert.Equal(t, []string{"closer"}, closed, "closed")
+	driver.AssertExpectations(t)
+
+	stop()
+	stop()
+}
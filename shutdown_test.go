@@ -0,0 +1,64 @@
+package l
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testCloser records the order it is closed on
+type testCloser struct {
+	name   string
+	err    error
+	closed *[]string
+}
+
+func (closer testCloser) Close(context.Context) error {
+	*closer.closed = append(*closer.closed, closer.name)
+	return closer.err
+}
+
+// isolateShutdown replaces the registered closers and LoggerDefault for a test
+func isolateShutdown(log Logger) func() {
+	shutdowns.Lock()
+	closers := shutdowns.closers
+	shutdowns.closers = nil
+	shutdowns.Unlock()
+	defaultLogger := LoggerDefault
+	LoggerDefault = log
+	return func() {
+		shutdowns.Lock()
+		shutdowns.closers = closers
+		shutdowns.Unlock()
+		LoggerDefault = defaultLogger
+	}
+}
+
+func TestShutdown(t *testing.T) {
+	var (
+		closed   []string
+		ctx      = context.Background()
+		errFirst = errors.New("err_first")
+		errLast  = errors.New("err_last")
+		driver   = newMockDriver()
+	)
+	defer isolateShutdown(New(driver))()
+	driver.On("Close", ctx).Return(nil).Twice()
+
+	RegisterShutdown(
+		testCloser{name: "first", err: errFirst, closed: &closed},
+		nil,
+		testCloser{name: "second", closed: &closed},
+		testCloser{name: "last", err: errLast, closed: &closed},
+	)
+	err := Shutdown(ctx)
+	assert.True(t, errors.Is(err, errFirst), "first close error")
+	assert.True(t, errors.Is(err, errLast), "last close error")
+	assert.Equal(t, []string{"last", "second", "first"}, closed, "close order")
+
+	assert.NoError(t, Shutdown(ctx), "second shutdown")
+	assert.Equal(t, []string{"last", "second", "first"}, closed, "closed once")
+	driver.AssertExpectations(t)
+}
@@ -0,0 +1,276 @@
+package l
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"os"
+	"time"
+)
+
+const (
+	// SlogNameKey is the attribute key of the logger name on the records written by NewSlogDriver
+	SlogNameKey = "logger"
+
+	// SlogLevelTrace is the slog level of TRACE
+	SlogLevelTrace = slog.LevelDebug - 4
+	// SlogLevelPanic is the slog level of PANIC
+	SlogLevelPanic = slog.LevelError + 4
+	// SlogLevelFatal is the slog level of FATAL
+	SlogLevelFatal = slog.LevelError + 8
+
+	// defaultTimeLayout is the ISO8601 layout of the entry time written by LoggerDefault
+	defaultTimeLayout = "2006-01-02T15:04:05.000Z0700"
+)
+
+// SlogLevel maps a Level to a slog.Level, unknown levels are slog.LevelDebug
+func SlogLevel(level Level) slog.Level {
+	switch level {
+	case TRACE:
+		return SlogLevelTrace
+	case INFO:
+		return slog.LevelInfo
+	case WARN:
+		return slog.LevelWarn
+	case ERROR:
+		return slog.LevelError
+	case PANIC:
+		return SlogLevelPanic
+	case FATAL:
+		return SlogLevelFatal
+	default:
+		return slog.LevelDebug
+	}
+}
+
+// LevelOfSlog maps a slog.Level to the closest Level at or below it
+func LevelOfSlog(level slog.Level) Level {
+	switch {
+	case level < slog.LevelDebug:
+		return TRACE
+	case level < slog.LevelInfo:
+		return DEBUG
+	case level < slog.LevelWarn:
+		return INFO
+	case level < slog.LevelError:
+		return WARN
+	case level < SlogLevelPanic:
+		return ERROR
+	case level < SlogLevelFatal:
+		return PANIC
+	default:
+		return FATAL
+	}
+}
+
+// toAttrs maps values to slog attributes, a namespace nests every following value into a slog group
+func toAttrs(values []Value) []slog.Attr {
+	attrs := make([]slog.Attr, 0, len(values))
+	for index, value := range values {
+		value = value.Resolve()
+		if value.Kind() == NamespaceKind {
+			return append(attrs, slog.Attr{Key: value.Name(), Value: slog.GroupValue(toAttrs(values[index+1:])...)})
+		}
+		attrs = append(attrs, toAttr(value))
+	}
+	return attrs
+}
+
+func toAttr(value Value) slog.Attr {
+	switch value.Kind() {
+	case StringKind:
+		return slog.String(value.Name(), value.AsString())
+	case Int64Kind:
+		return slog.Int64(value.Name(), value.AsInt64())
+	case Uint64Kind:
+		return slog.Uint64(value.Name(), value.AsUint64())
+	case Float64Kind:
+		return slog.Float64(value.Name(), value.AsFloat64())
+	case BoolKind:
+		return slog.Bool(value.Name(), value.AsBool())
+	case TimeKind:
+		return slog.Time(value.Name(), value.AsTime())
+	case DurationKind:
+		return slog.Duration(value.Name(), value.AsDuration())
+	case GroupKind:
+		return slog.Attr{Key: value.Name(), Value: slog.GroupValue(toAttrs(value.AsGroup())...)}
+	default:
+		return slog.Any(value.Name(), value.Any())
+	}
+}
+
+type slogDriver struct {
+	root    slog.Handler
+	handler slog.Handler
+	name    string
+	with    []Value
+	pending []Value
+}
+
+// NewSlogDriver creates a Driver which writes every log call as a slog.Record to the handler
+func NewSlogDriver(handler slog.Handler) Driver {
+	return slogDriver{root: handler, handler: handler}
+}
+
+func (driver slogDriver) Log(level Level, msg string) LogWriter {
+	slogLevel := SlogLevel(level)
+	if !driver.handler.Enabled(context.Background(), slogLevel) {
+		return nil
+	}
+	return &slogWriter{
+		driver: driver,
+		level:  slogLevel,
+		msg:    msg,
+		time:   time.Now(),
+	}
+}
+
+// With maps values to slog attributes, a namespace becomes a slog group holding every following attribute.
+// The lazy values are kept unresolved and added to every record
+func (driver slogDriver) With(values ...Value) Driver {
+	if len(values) == 0 {
+		return driver
+	}
+	values, driver.pending = SplitWith(driver.pending, values)
+	driver.handler = withAttrs(driver.handler, values)
+	driver.with = append(driver.with[:len(driver.with):len(driver.with)], values...)
+	return driver
+}
+
+// Named adds the name to the root handler, ahead of the groups of With, so the name is never nested
+func (driver slogDriver) Named(name string) Driver {
+	if name == "" {
+		return driver
+	}
+	driver.name = JoinName(driver.name, name)
+	handler := driver.root.WithAttrs([]slog.Attr{slog.String(SlogNameKey, driver.name)})
+	driver.handler = withAttrs(handler, driver.with)
+	return driver
+}
+
+// withAttrs adds the values to the handler, a namespace opens a slog group for every following value
+func withAttrs(handler slog.Handler, values []Value) slog.Handler {
+	start := 0
+	for index, value := range values {
+		if value.Kind() == NamespaceKind {
+			if attrs := toAttrs(values[start:index]); len(attrs) > 0 {
+				handler = handler.WithAttrs(attrs)
+			}
+			handler = handler.WithGroup(value.Name())
+			start = index + 1
+		}
+	}
+	if attrs := toAttrs(values[start:]); len(attrs) > 0 {
+		handler = handler.WithAttrs(attrs)
+	}
+	return handler
+}
+
+// Sync does nothing, a slog.Handler has no flush method
+func (driver slogDriver) Sync(context.Context) error {
+	return nil
+}
+
+// Close does nothing, the handler outputs are owned by the caller
+func (driver slogDriver) Close(context.Context) error {
+	return nil
+}
+
+type slogWriter struct {
+	driver slogDriver
+	level  slog.Level
+	msg    string
+	time   time.Time
+}
+
+func (writer *slogWriter) Write(values ...Value) {
+	writer.WriteContext(context.Background(), Caller{}, values...)
+}
+
+func (writer *slogWriter) WriteContext(ctx context.Context, caller Caller, values ...Value) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	record := slog.NewRecord(writer.time, writer.level, writer.msg, caller.PC())
+	if len(writer.driver.pending) > 0 {
+		values = append(writer.driver.pending[:len(writer.driver.pending):len(writer.driver.pending)], values...)
+	}
+	record.AddAttrs(toAttrs(values)...)
+	if err := writer.driver.handler.Handle(ctx, record); err != nil {
+		HandleError(err)
+	}
+}
+
+// replaceDefaultAttr writes the time, level and message of LoggerDefault records like the zap json layout
+func replaceDefaultAttr(groups []string, attr slog.Attr) slog.Attr {
+	if len(groups) > 0 {
+		return attr
+	}
+	switch attr.Key {
+	case slog.TimeKey:
+		if attr.Value.Kind() == slog.KindTime {
+			return slog.String(attr.Key, attr.Value.Time().Format(defaultTimeLayout))
+		}
+	case slog.LevelKey:
+		if level, ok := attr.Value.Any().(slog.Level); ok {
+			return slog.String(attr.Key, LevelOfSlog(level).String())
+		}
+	case slog.MessageKey:
+		attr.Key = "message"
+	}
+	return attr
+}
+
+// entryHandler encodes every record as json on its own buffer and writes it to the output with WriteEntry,
+// so the LoggerDefault entries are counted on Stats and written to os.Stderr when the output fails
+type entryHandler struct {
+	out     Output
+	options *slog.HandlerOptions
+	with    []func(slog.Handler) slog.Handler
+}
+
+func (handler entryHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= handler.options.Level.Level()
+}
+
+func (handler entryHandler) Handle(ctx context.Context, record slog.Record) error {
+	var (
+		buffer  bytes.Buffer
+		encoder slog.Handler = slog.NewJSONHandler(&buffer, handler.options)
+		level                = LevelOfSlog(record.Level)
+	)
+	for _, with := range handler.with {
+		encoder = with(encoder)
+	}
+	if err := encoder.Handle(ctx, record); err != nil {
+		FailEntry(level, err)
+		return nil
+	}
+	_, _ = WriteEntry(handler.out, level, buffer.Bytes())
+	return nil
+}
+
+func (handler entryHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return handler.append(func(encoder slog.Handler) slog.Handler { return encoder.WithAttrs(attrs) })
+}
+
+func (handler entryHandler) WithGroup(name string) slog.Handler {
+	return handler.append(func(encoder slog.Handler) slog.Handler { return encoder.WithGroup(name) })
+}
+
+func (handler entryHandler) append(with func(slog.Handler) slog.Handler) slog.Handler {
+	handler.with = append(handler.with[:len(handler.with):len(handler.with)], with)
+	return handler
+}
+
+// NewLoggerDefault creates a Logger writing json to STDOUT with a DEBUG threshold that can be changed at runtime
+func NewLoggerDefault() Logger {
+	handler := entryHandler{
+		out: stdOutput{Writer: os.Stdout},
+		options: &slog.HandlerOptions{
+			Level:       SlogLevelTrace,
+			ReplaceAttr: replaceDefaultAttr,
+		},
+	}
+	return NewWithLevel(NewSlogDriver(handler), NewAtomicLevel(DEBUG))
+}
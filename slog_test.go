@@ -0,0 +1,296 @@
+package l
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewLoggerDefault(t *testing.T) {
+	stdout, err := ioutil.TempFile("", "stdout")
+	assert.NoError(t, err, "stdout file")
+	defer os.Remove(stdout.Name())
+	defaultStdout := os.Stdout
+	os.Stdout = stdout
+	log := NewLoggerDefault()
+	os.Stdout = defaultStdout
+
+	assert.Equal(t, DEBUG, log.Level(), "default level")
+	log.Trace(context.Background(), "tracelog")
+	before := Stats()[INFO]
+	log.Named("api").Info(context.Background(), "infolog", String("key", "value"))
+	assert.Equal(t, before.Written+1, Stats()[INFO].Written, "written entries")
+	assert.NoError(t, log.SetLevel(TRACE), "set level")
+	log.Trace(context.Background(), "tracelog")
+	assert.NoError(t, stdout.Close(), "stdout close")
+
+	data, err := ioutil.ReadFile(stdout.Name())
+	assert.NoError(t, err, "stdout entries")
+	entries := strings.Split(strings.TrimSpace(string(data)), "\n")
+	assert.Len(t, entries, 2, "entries")
+
+	var fields map[string]interface{}
+	assert.NoError(t, json.Unmarshal([]byte(entries[0]), &fields), "json entry")
+	assert.Regexp(t, `^\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}\.\d{3}`, fields["time"], "time")
+	delete(fields, "time")
+	assert.Equal(t, map[string]interface{}{"level": "info", "message": "infolog", "logger": "api", "key": "value"}, fields, "info entry")
+	assert.Contains(t, entries[1], `"level":"trace"`, "trace entry")
+}
+
+func TestNewLoggerDefaultWriteError(t *testing.T) {
+	var reported []error
+	SetErrorHandler(func(err error) { reported = append(reported, err) })
+	defer SetErrorHandler(nil)
+	stdout, err := ioutil.TempFile("", "stdout")
+	assert.NoError(t, err, "stdout file")
+	defer os.Remove(stdout.Name())
+	assert.NoError(t, stdout.Close(), "close stdout file")
+	stderr, err := ioutil.TempFile("", "stderr")
+	assert.NoError(t, err, "stderr file")
+	defer os.Remove(stderr.Name())
+
+	defaultStdout, defaultStderr := os.Stdout, os.Stderr
+	os.Stdout, os.Stderr = stdout, stderr
+	before := Stats()[ERROR]
+	NewLoggerDefault().Error(context.Background(), "errorlog")
+	after := Stats()[ERROR]
+	os.Stdout, os.Stderr = defaultStdout, defaultStderr
+	assert.NoError(t, stderr.Close(), "close stderr file")
+
+	assert.Equal(t, before.Failed+1, after.Failed, "failed entries")
+	assert.Equal(t, before.Written, after.Written, "written entries")
+	assert.Len(t, reported, 1, "reported errors")
+	data, err := ioutil.ReadFile(stderr.Name())
+	assert.NoError(t, err, "stderr entry")
+	assert.Contains(t, string(data), `"message":"errorlog"`, "stderr entry")
+}
+
+type testSlogLevel struct {
+	name      string
+	level     Level
+	slogLevel slog.Level
+}
+
+func TestSlogLevel(test *testing.T) {
+	scenarios := []testSlogLevel{
+		{name: "Maps the trace level", level: TRACE, slogLevel: SlogLevelTrace},
+		{name: "Maps the debug level", level: DEBUG, slogLevel: slog.LevelDebug},
+		{name: "Maps the info level", level: INFO, slogLevel: slog.LevelInfo},
+		{name: "Maps the warn level", level: WARN, slogLevel: slog.LevelWarn},
+		{name: "Maps the error level", level: ERROR, slogLevel: slog.LevelError},
+		{name: "Maps the panic level", level: PANIC, slogLevel: SlogLevelPanic},
+		{name: "Maps the fatal level", level: FATAL, slogLevel: SlogLevelFatal},
+	}
+
+	for index, scenario := range scenarios {
+		test.Run(
+			fmt.Sprintf("[%d]-%s", index, scenario.name),
+			func(t *testing.T) {
+				assert.Equal(t, scenario.slogLevel, SlogLevel(scenario.level), "slog level")
+				assert.Equal(t, scenario.level, LevelOfSlog(scenario.slogLevel), "l level")
+				assert.Equal(t, scenario.level, LevelOfSlog(scenario.slogLevel+1), "l level between slog levels")
+			},
+		)
+	}
+}
+
+func TestSlogLevelBounds(t *testing.T) {
+	assert.Equal(t, slog.LevelDebug, SlogLevel(Level("invalid")), "invalid level")
+	assert.Equal(t, TRACE, LevelOfSlog(slog.LevelDebug-100), "lowest level")
+	assert.Equal(t, FATAL, LevelOfSlog(slog.LevelError+100), "highest level")
+}
+
+func newTestSlogHandler(buffer *bytes.Buffer, level slog.Level, source bool) slog.Handler {
+	return slog.NewJSONHandler(buffer, &slog.HandlerOptions{
+		Level:     level,
+		AddSource: source,
+		ReplaceAttr: func(groups []string, attr slog.Attr) slog.Attr {
+			if len(groups) == 0 && attr.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return attr
+		},
+	})
+}
+
+type testSlogDriver struct {
+	name     string
+	level    slog.Level
+	log      func(Logger)
+	expected []string
+}
+
+func TestSlogDriver(test *testing.T) {
+	scenarios := []testSlogDriver{
+		{
+			name:  "Writes typed values",
+			level: slog.LevelDebug,
+			log: func(log Logger) {
+				log.Info(context.Background(), "infolog",
+					String("string", "value"),
+					Int("int", -1),
+					Uint64("uint", 1),
+					Float64("float", 0.5),
+					Bool("bool", true),
+					Time("at", time.Date(2019, 10, 1, 12, 0, 0, 0, time.UTC)),
+					Duration("duration", time.Second),
+					Err(errors.New("errorvalue")),
+					Strings("strings", []string{"a"}),
+					Lazy("lazy", func() interface{} { return "lazy1" }),
+				)
+			},
+			expected: []string{
+				`{"level":"INFO","msg":"infolog","string":"value","int":-1,"uint":1,"float":0.5,"bool":true,` +
+					`"at":"2019-10-01T12:00:00Z","duration":1000000000,"error":"errorvalue","strings":["a"],"lazy":"lazy1"}`,
+			},
+		},
+		{
+			name:  "Resolves the lazy values of With on every record",
+			level: slog.LevelInfo,
+			log: func(log Logger) {
+				var calls int
+				child := log.With(String("id", "request1"), Lazy("calls", func() interface{} { calls++; return calls }), String("method", "GET"))
+				child.Debug(context.Background(), "debuglog")
+				child.Info(context.Background(), "infolog")
+				child.Info(context.Background(), "infolog", Int("status", 200))
+			},
+			expected: []string{
+				`{"level":"INFO","msg":"infolog","id":"request1","calls":1,"method":"GET"}`,
+				`{"level":"INFO","msg":"infolog","id":"request1","calls":2,"method":"GET","status":200}`,
+			},
+		},
+		{
+			name:  "Maps levels and filters with the handler level",
+			level: slog.LevelInfo,
+			log: func(log Logger) {
+				log.Trace(context.Background(), "tracelog")
+				log.Debug(context.Background(), "debuglog")
+				log.Warn(context.Background(), "warnlog")
+				log.Error(context.Background(), "errorlog")
+			},
+			expected: []string{
+				`{"level":"WARN","msg":"warnlog"}`,
+				`{"level":"ERROR","msg":"errorlog"}`,
+			},
+		},
+		{
+			name:  "Writes the trace level",
+			level: SlogLevelTrace,
+			log: func(log Logger) {
+				log.Trace(context.Background(), "tracelog")
+			},
+			expected: []string{
+				`{"level":"DEBUG-4","msg":"tracelog"}`,
+			},
+		},
+		{
+			name:  "Writes names, groups and namespaces",
+			level: slog.LevelDebug,
+			log: func(log Logger) {
+				log.Named("api").Named("db").
+					With(String("id", "request1"), Namespace("http"), String("method", "GET")).
+					Info(context.Background(), "infolog",
+						Group("response", Int("status", 200)),
+						Group("", Bool("inline", true)),
+						Namespace("user"),
+						String("id", "user1"),
+					)
+			},
+			expected: []string{
+				`{"level":"INFO","msg":"infolog","logger":"api.db","id":"request1","http":{"method":"GET",` +
+					`"response":{"status":200},"inline":true,"user":{"id":"user1"}}}`,
+			},
+		},
+		{
+			name:  "Keeps the name out of the With namespaces",
+			level: slog.LevelDebug,
+			log: func(log Logger) {
+				log.With(String("id", "request1"), Namespace("http"), String("method", "GET")).
+					Named("api").
+					Info(context.Background(), "infolog", Int("status", 200))
+			},
+			expected: []string{
+				`{"level":"INFO","msg":"infolog","logger":"api","id":"request1","http":{"method":"GET","status":200}}`,
+			},
+		},
+	}
+
+	for index, scenario := range scenarios {
+		test.Run(
+			fmt.Sprintf("[%d]-%s", index, scenario.name),
+			func(t *testing.T) {
+				var (
+					buffer = new(bytes.Buffer)
+					driver = NewSlogDriver(newTestSlogHandler(buffer, scenario.level, false))
+				)
+				scenario.log(New(driver))
+				assert.NoError(t, driver.Close(context.Background()), "close driver")
+				expected := ""
+				if len(scenario.expected) > 0 {
+					expected = strings.Join(scenario.expected, "\n") + "\n"
+				}
+				assert.Equal(t, expected, buffer.String(), "records")
+			},
+		)
+	}
+}
+
+type testSlogContextKey struct{}
+
+type testSlogContextHandler struct {
+	slog.Handler
+	contexts []context.Context
+}
+
+func (handler *testSlogContextHandler) Handle(ctx context.Context, record slog.Record) error {
+	handler.contexts = append(handler.contexts, ctx)
+	return handler.Handler.Handle(ctx, record)
+}
+
+func TestSlogDriverContextAndSource(t *testing.T) {
+	var (
+		buffer  = new(bytes.Buffer)
+		handler = &testSlogContextHandler{Handler: newTestSlogHandler(buffer, slog.LevelDebug, true)}
+		log     = New(NewSlogDriver(handler))
+		ctx     = WithValues(context.WithValue(context.Background(), testSlogContextKey{}, "value1"), String("requestid", "request1"))
+	)
+	_, file, line, _ := runtime.Caller(0)
+	log.Info(ctx, "infolog")
+
+	assert.Len(t, handler.contexts, 1, "handled contexts")
+	assert.Equal(t, "value1", handler.contexts[0].Value(testSlogContextKey{}), "context value")
+	assert.Contains(t, buffer.String(), fmt.Sprintf(`"file":"%s","line":%d`, file, line+1), "record source")
+	assert.Contains(t, buffer.String(), `"requestid":"request1"`, "context values")
+	assert.Equal(t, filepath.Base(file), "slog_test.go", "test file")
+}
+
+// testSlogFailingHandler fails every record
+type testSlogFailingHandler struct {
+	slog.Handler
+}
+
+func (handler testSlogFailingHandler) Handle(context.Context, slog.Record) error {
+	return errors.New("err_handle")
+}
+
+func TestSlogDriverHandleError(t *testing.T) {
+	var reported []error
+	SetErrorHandler(func(err error) { reported = append(reported, err) })
+	defer SetErrorHandler(nil)
+
+	log := New(NewSlogDriver(testSlogFailingHandler{Handler: newTestSlogHandler(new(bytes.Buffer), slog.LevelDebug, false)}))
+	log.Info(context.Background(), "infolog")
+	assert.Equal(t, []error{errors.New("err_handle")}, reported, "reported errors")
+}
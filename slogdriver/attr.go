@@ -0,0 +1,54 @@
+package slogdriver
+
+import (
+	"log/slog"
+
+	"github.com/rjansen/l"
+)
+
+// toValues maps slog attributes to values following the slog rules: empty attributes are dropped
+// and groups without a key are inlined
+func toValues(attrs []slog.Attr) []l.Value {
+	values := make([]l.Value, 0, len(attrs))
+	for _, attr := range attrs {
+		values = appendValue(values, attr)
+	}
+	return values
+}
+
+func appendValue(values []l.Value, attr slog.Attr) []l.Value {
+	attr.Value = attr.Value.Resolve()
+	if attr.Equal(slog.Attr{}) {
+		return values
+	}
+	switch attr.Value.Kind() {
+	case slog.KindString:
+		return append(values, l.String(attr.Key, attr.Value.String()))
+	case slog.KindInt64:
+		return append(values, l.Int64(attr.Key, attr.Value.Int64()))
+	case slog.KindUint64:
+		return append(values, l.Uint64(attr.Key, attr.Value.Uint64()))
+	case slog.KindFloat64:
+		return append(values, l.Float64(attr.Key, attr.Value.Float64()))
+	case slog.KindBool:
+		return append(values, l.Bool(attr.Key, attr.Value.Bool()))
+	case slog.KindDuration:
+		return append(values, l.Duration(attr.Key, attr.Value.Duration()))
+	case slog.KindTime:
+		return append(values, l.Time(attr.Key, attr.Value.Time()))
+	case slog.KindGroup:
+		group := attr.Value.Group()
+		if len(group) == 0 {
+			return values
+		}
+		if attr.Key == "" {
+			for _, groupAttr := range group {
+				values = appendValue(values, groupAttr)
+			}
+			return values
+		}
+		return append(values, l.Group(attr.Key, toValues(group)...))
+	default:
+		return append(values, l.NewValue(attr.Key, attr.Value.Any()))
+	}
+}
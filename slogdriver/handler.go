@@ -0,0 +1,94 @@
+// Package slogdriver writes slog records through any l.Logger with NewHandler,
+// the l driver writing through any slog.Handler is l.NewSlogDriver
+package slogdriver
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/rjansen/l"
+)
+
+type handler struct {
+	logger l.Logger
+	// groups are the names of WithGroup waiting for attributes, a group without attributes is not written
+	groups []string
+}
+
+// NewHandler creates a slog.Handler which writes every record through the logger.
+// Records above slog.LevelError are written at l.ERROR, a slog call never panics or exits the process.
+func NewHandler(logger l.Logger) slog.Handler {
+	return handler{logger: logger}
+}
+
+func (handler handler) Enabled(_ context.Context, level slog.Level) bool {
+	return handler.logger.Enabled(handlerLevel(level))
+}
+
+func (handler handler) Handle(ctx context.Context, record slog.Record) error {
+	values := make([]l.Value, 0, record.NumAttrs())
+	record.Attrs(func(attr slog.Attr) bool {
+		values = appendValue(values, attr)
+		return true
+	})
+	if len(values) > 0 {
+		values = handler.openGroups(values)
+	}
+	level := handlerLevel(record.Level)
+	if logger, ok := handler.logger.(l.CallerLogger); ok {
+		logger.LogCaller(ctx, level, l.NewCaller(record.PC), record.Message, values...)
+		return nil
+	}
+	switch level {
+	case l.TRACE:
+		handler.logger.Trace(ctx, record.Message, values...)
+	case l.DEBUG:
+		handler.logger.Debug(ctx, record.Message, values...)
+	case l.INFO:
+		handler.logger.Info(ctx, record.Message, values...)
+	case l.WARN:
+		handler.logger.Warn(ctx, record.Message, values...)
+	default:
+		handler.logger.Error(ctx, record.Message, values...)
+	}
+	return nil
+}
+
+func (handler handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	values := toValues(attrs)
+	if len(values) == 0 {
+		return handler
+	}
+	return NewHandler(handler.logger.With(handler.openGroups(values)...))
+}
+
+// WithGroup nests every following attribute under the name using an l.Namespace,
+// the namespace is opened only when attributes are added to the group
+func (handler handler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return handler
+	}
+	groups := make([]string, len(handler.groups), len(handler.groups)+1)
+	copy(groups, handler.groups)
+	handler.groups = append(groups, name)
+	return handler
+}
+
+// openGroups prefixes the values with a namespace for every pending group
+func (handler handler) openGroups(values []l.Value) []l.Value {
+	if len(handler.groups) == 0 {
+		return values
+	}
+	opened := make([]l.Value, 0, len(handler.groups)+len(values))
+	for _, group := range handler.groups {
+		opened = append(opened, l.Namespace(group))
+	}
+	return append(opened, values...)
+}
+
+func handlerLevel(level slog.Level) l.Level {
+	if level >= l.SlogLevelPanic {
+		return l.ERROR
+	}
+	return l.LevelOfSlog(level)
+}
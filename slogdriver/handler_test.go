@@ -0,0 +1,187 @@
+package slogdriver
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"runtime"
+	"strings"
+	"testing"
+	"testing/slogtest"
+	"time"
+
+	"github.com/rjansen/l"
+	"github.com/rjansen/l/jsondriver"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	lmock "github.com/rjansen/l/mock"
+)
+
+var testTime = time.Date(2019, 10, 1, 12, 30, 15, 123000000, time.UTC)
+
+func newTestLogger(buffer *bytes.Buffer, level l.Level, options ...jsondriver.Option) l.Logger {
+	options = append([]jsondriver.Option{
+		jsondriver.WithClock(func() time.Time { return testTime }),
+		jsondriver.WithStacktraceLevel(l.FATAL),
+	}, options...)
+	return l.NewWithLevel(jsondriver.New(buffer, options...), l.NewAtomicLevel(level))
+}
+
+type testHandler struct {
+	name     string
+	level    l.Level
+	log      func(*slog.Logger)
+	expected []string
+}
+
+func TestHandler(test *testing.T) {
+	scenarios := []testHandler{
+		{
+			name:  "Writes attributes",
+			level: l.TRACE,
+			log: func(log *slog.Logger) {
+				log.Info("infolog",
+					slog.String("string", "value"),
+					slog.Int("int", -1),
+					slog.Uint64("uint", 1),
+					slog.Float64("float", 0.5),
+					slog.Bool("bool", true),
+					slog.Time("time", testTime),
+					slog.Duration("duration", time.Second),
+					slog.Any("any", []int{1, 2}),
+					slog.Attr{},
+				)
+			},
+			expected: []string{
+				`{"level":"info","time":"2019-10-01T12:30:15.123Z","message":"infolog","string":"value","int":-1,"uint":1,` +
+					`"float":0.5,"bool":true,"time":"2019-10-01T12:30:15.123Z","duration":"1s","any":[1,2]}`,
+			},
+		},
+		{
+			name:  "Maps levels and filters with the logger level",
+			level: l.INFO,
+			log: func(log *slog.Logger) {
+				log.Log(context.Background(), l.SlogLevelTrace, "tracelog")
+				log.Debug("debuglog")
+				log.Warn("warnlog")
+				log.Error("errorlog")
+				log.Log(context.Background(), l.SlogLevelFatal, "fatallog")
+			},
+			expected: []string{
+				`{"level":"warn","time":"2019-10-01T12:30:15.123Z","message":"warnlog"}`,
+				`{"level":"error","time":"2019-10-01T12:30:15.123Z","message":"errorlog"}`,
+				`{"level":"error","time":"2019-10-01T12:30:15.123Z","message":"fatallog"}`,
+			},
+		},
+		{
+			name:  "Writes groups",
+			level: l.TRACE,
+			log: func(log *slog.Logger) {
+				log.With(slog.String("id", "request1")).WithGroup("http").With(slog.String("method", "GET")).
+					Debug("debuglog",
+						slog.Group("response", slog.Int("status", 200)),
+						slog.Group("", slog.Bool("inline", true)),
+						slog.Group("empty"),
+					)
+			},
+			expected: []string{
+				`{"level":"debug","time":"2019-10-01T12:30:15.123Z","message":"debuglog","id":"request1",` +
+					`"http":{"method":"GET","response":{"status":200},"inline":true}}`,
+			},
+		},
+	}
+
+	for index, scenario := range scenarios {
+		test.Run(
+			fmt.Sprintf("[%d]-%s", index, scenario.name),
+			func(t *testing.T) {
+				buffer := new(bytes.Buffer)
+				scenario.log(slog.New(NewHandler(newTestLogger(buffer, scenario.level))))
+				assert.Equal(t, strings.Join(scenario.expected, "\n")+"\n", buffer.String(), "entries")
+			},
+		)
+	}
+}
+
+func TestHandlerSlogTest(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	err := slogtest.TestHandler(NewHandler(newTestLogger(buffer, l.INFO)), func() []map[string]any {
+		var entries []map[string]any
+		for _, line := range bytes.Split(bytes.TrimSpace(buffer.Bytes()), []byte("\n")) {
+			var entry map[string]any
+			assert.NoError(t, json.Unmarshal(line, &entry), "json entry %s", line)
+			entry[slog.MessageKey] = entry["message"]
+			delete(entry, "message")
+			entries = append(entries, entry)
+		}
+		return entries
+	})
+	// the entry time comes from the driver clock, so a zero Record.Time is still written
+	var problems []error
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		for _, problem := range joined.Unwrap() {
+			if !strings.Contains(problem.Error(), "zero Record.Time") {
+				problems = append(problems, problem)
+			}
+		}
+	} else if err != nil {
+		problems = append(problems, err)
+	}
+	assert.Empty(t, problems, "slogtest")
+}
+
+func TestHandlerContextAndCaller(t *testing.T) {
+	var (
+		buffer = new(bytes.Buffer)
+		log    = slog.New(NewHandler(newTestLogger(buffer, l.TRACE, jsondriver.WithCaller(true))))
+		ctx    = l.WithValues(context.Background(), l.String("requestid", "request1"))
+	)
+	_, _, line, _ := runtime.Caller(0)
+	log.InfoContext(ctx, "infolog")
+	assert.Equal(t,
+		fmt.Sprintf(`{"level":"info","time":"2019-10-01T12:30:15.123Z","caller":"slogdriver/handler_test.go:%d",`+
+			`"message":"infolog","requestid":"request1"}`+"\n", line+1),
+		buffer.String(),
+		"entry",
+	)
+}
+
+func TestHandlerLogger(t *testing.T) {
+	logger := lmock.NewMockLogger()
+	logger.On("Enabled", mock.Anything).Return(true)
+	for _, method := range []string{"Trace", "Debug", "Info", "Warn", "Error"} {
+		logger.On(method, mock.Anything, strings.ToLower(method)+"log", mock.Anything).Once()
+	}
+
+	log := slog.New(NewHandler(logger))
+	log.Log(context.Background(), l.SlogLevelTrace, "tracelog")
+	log.Debug("debuglog")
+	log.Info("infolog")
+	log.Warn("warnlog", slog.String("key", "value"))
+	log.Log(context.Background(), l.SlogLevelPanic, "errorlog")
+
+	logger.AssertExpectations(t)
+	logger.AssertCalled(t, "Warn", mock.Anything, "warnlog", []l.Value{l.String("key", "value")})
+}
+
+func TestRoundTrip(t *testing.T) {
+	var (
+		buffer = new(bytes.Buffer)
+		log    = l.New(l.NewSlogDriver(NewHandler(newTestLogger(buffer, l.TRACE))))
+	)
+	log.Named("api").With(l.Namespace("http")).Warn(context.Background(), "warnlog", l.Int("status", 200))
+	assert.Equal(t,
+		`{"level":"warn","time":"2019-10-01T12:30:15.123Z","message":"warnlog","logger":"api","http":{"status":200}}`+"\n",
+		buffer.String(),
+		"entry",
+	)
+}
+
+func TestHandlerLevel(t *testing.T) {
+	assert.Equal(t, l.ERROR, handlerLevel(l.SlogLevelFatal), "handler highest level")
+	assert.Equal(t, l.WARN, handlerLevel(slog.LevelWarn), "handler warn level")
+	assert.Equal(t, l.TRACE, handlerLevel(l.SlogLevelTrace), "handler trace level")
+}
@@ -0,0 +1,115 @@
+package l
+
+import (
+	"context"
+	"errors"
+)
+
+type teeDriver struct {
+	drivers []Driver
+}
+
+// NewTeeDriver creates a Driver which writes every log call to all the drivers, like zap json and a remote sink
+func NewTeeDriver(drivers ...Driver) Driver {
+	tee := teeDriver{drivers: make([]Driver, 0, len(drivers))}
+	for _, driver := range drivers {
+		if driver == nil {
+			continue
+		}
+		// nested tees are flattened so a log call checks every driver once
+		if nested, ok := driver.(teeDriver); ok {
+			tee.drivers = append(tee.drivers, nested.drivers...)
+			continue
+		}
+		tee.drivers = append(tee.drivers, driver)
+	}
+	return tee
+}
+
+// Log returns a writer for the drivers which have the level enabled, nil when no driver has it enabled
+func (tee teeDriver) Log(level Level, msg string) LogWriter {
+	var writers []LogWriter
+	for _, driver := range tee.drivers {
+		if writer := driver.Log(level, msg); writer != nil {
+			writers = append(writers, writer)
+		}
+	}
+	switch len(writers) {
+	case 0:
+		return nil
+	case 1:
+		return writers[0]
+	default:
+		return teeWriter(writers)
+	}
+}
+
+func (tee teeDriver) With(values ...Value) Driver {
+	if len(values) == 0 {
+		return tee
+	}
+	child := teeDriver{drivers: make([]Driver, len(tee.drivers))}
+	for index, driver := range tee.drivers {
+		child.drivers[index] = driver.With(values...)
+	}
+	return child
+}
+
+func (tee teeDriver) Named(name string) Driver {
+	if name == "" {
+		return tee
+	}
+	child := teeDriver{drivers: make([]Driver, len(tee.drivers))}
+	for index, driver := range tee.drivers {
+		child.drivers[index] = driver.Named(name)
+	}
+	return child
+}
+
+// Sync syncs every driver and returns the errors of all of them
+func (tee teeDriver) Sync(ctx context.Context) error {
+	var errs []error
+	for _, driver := range tee.drivers {
+		if err := driver.Sync(ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Close closes every driver, a failure does not stop the next drivers and the errors of all of them are returned
+func (tee teeDriver) Close(ctx context.Context) error {
+	var errs []error
+	for _, driver := range tee.drivers {
+		if err := driver.Close(ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// teeWriter writes to several writers, it is a ContextWriter so each writer receives the context and caller it uses
+type teeWriter []LogWriter
+
+func (writers teeWriter) Write(values ...Value) {
+	values = resolveValues(values)
+	for _, writer := range writers {
+		writer.Write(values...)
+	}
+}
+
+func (writers teeWriter) WriteContext(ctx context.Context, caller Caller, values ...Value) {
+	values = resolveValues(values)
+	for _, writer := range writers {
+		writeContext(writer, ctx, caller, values)
+	}
+}
+
+// resolveValues resolves the lazy values, and the ones of the groups, once for all the writers
+func resolveValues(values []Value) []Value {
+	resolved := make([]Value, len(values))
+	for index, value := range values {
+		resolved[index] = value.resolveAll()
+	}
+	return resolved
+}
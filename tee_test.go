@@ -0,0 +1,168 @@
+package l
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestSlogDriver creates a slog driver writing the json records enabled by the level to the buffer
+func newTestSlogDriver(buffer *bytes.Buffer, level Level) Driver {
+	return NewSlogDriver(slog.NewJSONHandler(buffer, &slog.HandlerOptions{Level: SlogLevel(level)}))
+}
+
+// decodeSlogRecords decodes the json records written by the slog driver
+func decodeSlogRecords(t *testing.T, buffer *bytes.Buffer) []map[string]interface{} {
+	var records []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(buffer.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var record map[string]interface{}
+		assert.NoError(t, json.Unmarshal([]byte(line), &record), "json record")
+		records = append(records, record)
+	}
+	return records
+}
+
+type testTeeDriver struct {
+	name     string
+	log      func(Logger)
+	expected [][]string
+}
+
+func TestTeeDriver(test *testing.T) {
+	scenarios := []testTeeDriver{
+		{
+			name: "Writes only to the drivers with the level enabled",
+			log: func(log Logger) {
+				log.Debug(context.Background(), "debuglog")
+				log.Info(context.Background(), "infolog")
+				log.Error(context.Background(), "errorlog")
+			},
+			expected: [][]string{
+				{"infolog", "errorlog"},
+				{"errorlog"},
+				{"debuglog", "infolog", "errorlog"},
+			},
+		},
+		{
+			name: "Does not write a level disabled on every driver",
+			log: func(log Logger) {
+				log.Trace(context.Background(), "tracelog")
+			},
+			expected: [][]string{nil, nil, nil},
+		},
+		{
+			name: "Writes named child entries with values to every driver",
+			log: func(log Logger) {
+				log.Named("api").With(String("key", "value")).Error(context.Background(), "errorlog")
+			},
+			expected: [][]string{
+				{"api:errorlog:value"},
+				{"api:errorlog:value"},
+				{"api:errorlog:value"},
+			},
+		},
+	}
+
+	for index, scenario := range scenarios {
+		test.Run(
+			fmt.Sprintf("[%d]-%s", index, scenario.name),
+			func(t *testing.T) {
+				var (
+					infoBuffer  = new(bytes.Buffer)
+					errorBuffer = new(bytes.Buffer)
+					debugBuffer = new(bytes.Buffer)
+					driver      = NewTeeDriver(
+						newTestSlogDriver(infoBuffer, INFO),
+						NewTeeDriver(newTestSlogDriver(errorBuffer, ERROR), nil),
+						newTestSlogDriver(debugBuffer, DEBUG),
+					)
+				)
+				scenario.log(New(driver))
+
+				for bufferIndex, buffer := range []*bytes.Buffer{infoBuffer, errorBuffer, debugBuffer} {
+					var messages []string
+					for _, record := range decodeSlogRecords(t, buffer) {
+						message := record[slog.MessageKey].(string)
+						if name, ok := record[SlogNameKey]; ok {
+							message = fmt.Sprintf("%s:%s:%s", name, message, record["key"])
+						}
+						messages = append(messages, message)
+					}
+					assert.Equal(t, scenario.expected[bufferIndex], messages, "driver %d messages", bufferIndex)
+				}
+			},
+		)
+	}
+}
+
+func TestTeeDriverWriters(t *testing.T) {
+	var (
+		callerWriter  = new(testCallerWriter)
+		contextWriter = new(testContextWriter)
+		buffer        = new(bytes.Buffer)
+		calls         int
+		log           = New(NewTeeDriver(
+			testCallerDriver{writer: callerWriter},
+			testContextDriver{writer: contextWriter},
+			newTestSlogDriver(buffer, DEBUG),
+		))
+		ctx = WithValues(context.Background(), String("requestid", "request1"))
+	)
+	line := currentLine() + 1
+	log.Info(ctx, "infolog", Lazy("lazy", func() interface{} { calls++; return calls }))
+
+	expected := []Value{String("requestid", "request1"), NewValue("lazy", 1)}
+	assert.Equal(t, 1, calls, "lazy calls")
+	assert.Equal(t, line, callerWriter.caller.Frame().Line, "caller writer line")
+	assert.Equal(t, expected, callerWriter.values, "caller writer values")
+	assert.Equal(t, ctx, contextWriter.ctx, "context writer context")
+	assert.Equal(t, line, contextWriter.caller.Frame().Line, "context writer line")
+	assert.Equal(t, expected, contextWriter.values, "context writer values")
+	records := decodeSlogRecords(t, buffer)
+	assert.Len(t, records, 1, "slog records")
+	assert.Equal(t, "request1", records[0]["requestid"], "slog context value")
+	assert.Equal(t, float64(1), records[0]["lazy"], "slog lazy value")
+}
+
+func TestTeeDriverSingleWriter(t *testing.T) {
+	var (
+		writer = newMockLogWriter()
+		first  = newMockDriver()
+		second = newMockDriver()
+		tee    = NewTeeDriver(first, second)
+	)
+	first.On("Log", INFO, "infolog").Return(writer)
+	second.On("Log", INFO, "infolog").Return(nil)
+	assert.Exactly(t, writer, tee.Log(INFO, "infolog"), "single writer")
+
+	var (
+		ctx       = context.Background()
+		errFirst  = errors.New("err_first")
+		errSecond = errors.New("err_second")
+	)
+	first.On("Sync", ctx).Return(nil).Once()
+	second.On("Sync", ctx).Return(errSecond).Once()
+	assert.True(t, errors.Is(tee.Sync(ctx), errSecond), "sync error")
+
+	first.On("Close", ctx).Return(errFirst).Once()
+	second.On("Close", ctx).Return(errSecond).Once()
+	err := tee.Close(ctx)
+	assert.True(t, errors.Is(err, errFirst), "first close error")
+	assert.True(t, errors.Is(err, errSecond), "second close error")
+	first.AssertExpectations(t)
+	second.AssertExpectations(t)
+
+	assert.Exactly(t, tee, tee.With(), "empty with")
+	assert.Exactly(t, tee, tee.Named(""), "empty name")
+	assert.Nil(t, NewTeeDriver().Log(INFO, "infolog"), "empty tee writer")
+}
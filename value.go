@@ -0,0 +1,346 @@
+package l
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// Kind is the type of the data held by a Value
+type Kind uint8
+
+const (
+	// AnyKind is a Value created by NewValue or Any, drivers encode it by reflection
+	AnyKind Kind = iota
+	// StringKind is a Value created by String
+	StringKind
+	// Int64Kind is a Value created by Int or Int64
+	Int64Kind
+	// Uint64Kind is a Value created by Uint64
+	Uint64Kind
+	// Float64Kind is a Value created by Float64
+	Float64Kind
+	// BoolKind is a Value created by Bool
+	BoolKind
+	// TimeKind is a Value created by Time
+	TimeKind
+	// DurationKind is a Value created by Duration
+	DurationKind
+	// ErrorKind is a Value created by Err or NamedErr
+	ErrorKind
+	// BytesKind is a Value created by Bytes
+	BytesKind
+	// StringsKind is a Value created by Strings
+	StringsKind
+	// GroupKind is a Value created by Group, it holds nested values
+	GroupKind
+	// NamespaceKind is a Value created by Namespace, every value after it is nested under its name
+	NamespaceKind
+	// LazyKind is a Value created by Lazy, it must be resolved before encoding
+	LazyKind
+)
+
+// maxResolveDepth limits the chain of LogValuers and Lazy functions resolved for a single Value
+const maxResolveDepth = 100
+
+// LogValuer is implemented by types which supply their own log representation.
+// A LogValuer logged by NewValue or returned by Lazy is resolved only when the log level is enabled,
+// the resolved Value keeps the name the LogValuer was logged with.
+type LogValuer interface {
+	LogValue() Value
+}
+
+var kindNames = [...]string{
+	AnyKind:       "any",
+	StringKind:    "string",
+	Int64Kind:     "int64",
+	Uint64Kind:    "uint64",
+	Float64Kind:   "float64",
+	BoolKind:      "bool",
+	TimeKind:      "time",
+	DurationKind:  "duration",
+	ErrorKind:     "error",
+	BytesKind:     "bytes",
+	StringsKind:   "strings",
+	GroupKind:     "group",
+	NamespaceKind: "namespace",
+	LazyKind:      "lazy",
+}
+
+func (k Kind) String() string {
+	if int(k) < len(kindNames) {
+		return kindNames[k]
+	}
+	return "unknown"
+}
+
+// Value is a named log field, the typed constructors keep scalar data unboxed so drivers can encode it without reflection
+type Value struct {
+	name  string
+	kind  Kind
+	num   uint64
+	str   string
+	value interface{}
+}
+
+// NewValue creates a Value of any type, prefer the typed constructors on hot paths
+func NewValue(name string, value interface{}) Value {
+	return Value{name: name, value: value}
+}
+
+// Any is an alias for NewValue
+func Any(name string, value interface{}) Value {
+	return NewValue(name, value)
+}
+
+func String(name string, value string) Value {
+	return Value{name: name, kind: StringKind, str: value}
+}
+
+func Int(name string, value int) Value {
+	return Int64(name, int64(value))
+}
+
+func Int64(name string, value int64) Value {
+	return Value{name: name, kind: Int64Kind, num: uint64(value)}
+}
+
+func Uint64(name string, value uint64) Value {
+	return Value{name: name, kind: Uint64Kind, num: value}
+}
+
+func Float64(name string, value float64) Value {
+	return Value{name: name, kind: Float64Kind, num: math.Float64bits(value)}
+}
+
+func Bool(name string, value bool) Value {
+	var num uint64
+	if value {
+		num = 1
+	}
+	return Value{name: name, kind: BoolKind, num: num}
+}
+
+var (
+	// minTime and maxTime bound the times whose wall clock fits in UnixNano
+	minTime = time.Unix(0, math.MinInt64)
+	maxTime = time.Unix(0, math.MaxInt64)
+)
+
+// Time creates a Value from the wall clock of the time, the monotonic clock reading is dropped.
+// A time outside the UnixNano range, like the zero time, is kept as is
+func Time(name string, value time.Time) Value {
+	if value.Before(minTime) || value.After(maxTime) {
+		return Value{name: name, kind: TimeKind, value: value.Round(0)}
+	}
+	return Value{name: name, kind: TimeKind, num: uint64(value.UnixNano()), value: value.Location()}
+}
+
+func Duration(name string, value time.Duration) Value {
+	return Value{name: name, kind: DurationKind, num: uint64(value)}
+}
+
+// Err creates a Value named error
+func Err(err error) Value {
+	return NamedErr("error", err)
+}
+
+func NamedErr(name string, err error) Value {
+	return Value{name: name, kind: ErrorKind, value: err}
+}
+
+func Bytes(name string, value []byte) Value {
+	return Value{name: name, kind: BytesKind, value: value}
+}
+
+func Strings(name string, value []string) Value {
+	return Value{name: name, kind: StringsKind, value: value}
+}
+
+// Group creates a Value which nests the provided values under the name,
+// a group without values is dropped and a group without name has its values inlined
+func Group(name string, values ...Value) Value {
+	return Value{name: name, kind: GroupKind, value: values}
+}
+
+// Namespace creates a Value which nests every following value of the same log call or With under the name
+func Namespace(name string) Value {
+	return Value{name: name, kind: NamespaceKind}
+}
+
+// Lazy creates a Value whose data is computed by the function only when the log level is enabled
+func Lazy(name string, value func() interface{}) Value {
+	return Value{name: name, kind: LazyKind, value: value}
+}
+
+// Resolve evaluates Lazy values and LogValuers, drivers call it after the level check and before encoding
+func (v Value) Resolve() Value {
+	for depth := 0; depth < maxResolveDepth; depth++ {
+		if !v.resolvable() {
+			return v
+		}
+		v = v.resolveNext()
+	}
+	return NewValue(v.name, fmt.Sprintf("err_log_value_too_deep{Depth=%d}", maxResolveDepth))
+}
+
+// SplitWith splits the values of a Driver.With into the values the driver encodes right away and the values it keeps
+// to resolve on every write: a Lazy value or LogValuer, and every value after it to keep the order, stay pending.
+// The pending values of the parent driver are kept ahead of the returned ones
+func SplitWith(pending []Value, values []Value) (encode []Value, keep []Value) {
+	if len(pending) == 0 {
+		index := 0
+		for index < len(values) && !values[index].deferred() {
+			index++
+		}
+		encode, values = values[:index], values[index:]
+	}
+	if len(values) == 0 {
+		return encode, pending
+	}
+	keep = make([]Value, 0, len(pending)+len(values))
+	return encode, append(append(keep, pending...), values...)
+}
+
+// deferred reports whether the value, or a value of its group, is resolved only when written
+func (v Value) deferred() bool {
+	if v.kind == GroupKind {
+		for _, value := range v.AsGroup() {
+			if value.deferred() {
+				return true
+			}
+		}
+		return false
+	}
+	return v.resolvable()
+}
+
+// resolveAll resolves the value and, after it, the values of its group
+func (v Value) resolveAll() Value {
+	v = v.Resolve()
+	if v.kind == GroupKind && v.deferred() {
+		v.value = resolveValues(v.AsGroup())
+	}
+	return v
+}
+
+func (v Value) resolvable() bool {
+	switch v.kind {
+	case LazyKind:
+		return true
+	case AnyKind:
+		_, ok := v.value.(LogValuer)
+		return ok
+	default:
+		return false
+	}
+}
+
+func (v Value) resolveNext() (resolved Value) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			resolved = NewValue(v.name, fmt.Sprintf("err_log_value_panic{Panic=%v}", recovered))
+		}
+	}()
+	var next interface{}
+	switch value := v.value.(type) {
+	case LogValuer:
+		next = value.LogValue()
+	case func() interface{}:
+		if value != nil {
+			next = value()
+		}
+	}
+	if nextValue, ok := next.(Value); ok {
+		resolved = nextValue
+	} else {
+		resolved = NewValue(v.name, next)
+	}
+	resolved.name = v.name
+	return resolved
+}
+
+func (v Value) Name() string {
+	return v.name
+}
+
+func (v Value) Kind() Kind {
+	return v.kind
+}
+
+func (v Value) AsString() string {
+	return v.str
+}
+
+func (v Value) AsInt64() int64 {
+	return int64(v.num)
+}
+
+func (v Value) AsUint64() uint64 {
+	return v.num
+}
+
+func (v Value) AsFloat64() float64 {
+	return math.Float64frombits(v.num)
+}
+
+func (v Value) AsBool() bool {
+	return v.num == 1
+}
+
+func (v Value) AsTime() time.Time {
+	switch value := v.value.(type) {
+	case *time.Location:
+		return time.Unix(0, int64(v.num)).In(value)
+	case time.Time:
+		return value
+	default:
+		return time.Unix(0, int64(v.num))
+	}
+}
+
+func (v Value) AsDuration() time.Duration {
+	return time.Duration(v.num)
+}
+
+func (v Value) AsError() error {
+	err, _ := v.value.(error)
+	return err
+}
+
+func (v Value) AsBytes() []byte {
+	bytes, _ := v.value.([]byte)
+	return bytes
+}
+
+func (v Value) AsStrings() []string {
+	strings, _ := v.value.([]string)
+	return strings
+}
+
+func (v Value) AsGroup() []Value {
+	values, _ := v.value.([]Value)
+	return values
+}
+
+// Any returns the data of the Value as an interface{}, boxing the typed kinds
+func (v Value) Any() interface{} {
+	switch v.kind {
+	case StringKind:
+		return v.AsString()
+	case Int64Kind:
+		return v.AsInt64()
+	case Uint64Kind:
+		return v.AsUint64()
+	case Float64Kind:
+		return v.AsFloat64()
+	case BoolKind:
+		return v.AsBool()
+	case TimeKind:
+		return v.AsTime()
+	case DurationKind:
+		return v.AsDuration()
+	default:
+		return v.value
+	}
+}
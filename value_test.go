@@ -0,0 +1,267 @@
+package l
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testValue struct {
+	name     string
+	value    Value
+	kind     Kind
+	expected interface{}
+}
+
+func TestValue(test *testing.T) {
+	var (
+		timeNow = time.Date(2019, 10, 1, 12, 30, 15, 999, time.FixedZone("BRT", -3*60*60))
+		err     = errors.New("errorvalue")
+		anyType = new(struct{})
+	)
+	scenarios := []testValue{
+		{name: "Creates an any Value", value: NewValue("anyvalue", anyType), kind: AnyKind, expected: anyType},
+		{name: "Creates an any Value by alias", value: Any("anyvalue", 999), kind: AnyKind, expected: 999},
+		{name: "Creates a string Value", value: String("stringvalue", "string1"), kind: StringKind, expected: "string1"},
+		{name: "Creates an int Value", value: Int("intvalue", -999), kind: Int64Kind, expected: int64(-999)},
+		{name: "Creates an int64 Value", value: Int64("int64value", 999), kind: Int64Kind, expected: int64(999)},
+		{name: "Creates an uint64 Value", value: Uint64("uint64value", 999), kind: Uint64Kind, expected: uint64(999)},
+		{name: "Creates a float64 Value", value: Float64("float64value", -999.99), kind: Float64Kind, expected: -999.99},
+		{name: "Creates a true bool Value", value: Bool("boolvalue", true), kind: BoolKind, expected: true},
+		{name: "Creates a false bool Value", value: Bool("boolvalue", false), kind: BoolKind, expected: false},
+		{name: "Creates a time Value", value: Time("timevalue", timeNow), kind: TimeKind, expected: timeNow},
+		{name: "Creates a duration Value", value: Duration("durationvalue", time.Second*9), kind: DurationKind, expected: time.Second * 9},
+		{name: "Creates an error Value", value: Err(err), kind: ErrorKind, expected: err},
+		{name: "Creates a named error Value", value: NamedErr("namederror", err), kind: ErrorKind, expected: err},
+		{name: "Creates a bytes Value", value: Bytes("bytesvalue", []byte("bytes1")), kind: BytesKind, expected: []byte("bytes1")},
+		{name: "Creates a strings Value", value: Strings("stringsvalue", []string{"a", "b"}), kind: StringsKind, expected: []string{"a", "b"}},
+		{name: "Creates a group Value", value: Group("groupvalue", String("key", "value")), kind: GroupKind, expected: []Value{String("key", "value")}},
+		{name: "Creates a namespace Value", value: Namespace("namespacevalue"), kind: NamespaceKind, expected: nil},
+	}
+
+	for index, scenario := range scenarios {
+		test.Run(
+			fmt.Sprintf("[%d]-%s", index, scenario.name),
+			func(t *testing.T) {
+				assert.Equal(t, scenario.kind, scenario.value.Kind(), "value kind")
+				assert.Equal(t, scenario.expected, scenario.value.Any(), "value data")
+			},
+		)
+	}
+}
+
+type testTimeValue struct {
+	name  string
+	value time.Time
+}
+
+func TestTimeValue(test *testing.T) {
+	scenarios := []testTimeValue{
+		{name: "Keeps the zero time", value: time.Time{}},
+		{name: "Keeps a time before the UnixNano range", value: time.Date(1600, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{name: "Keeps a time after the UnixNano range", value: time.Date(3000, 1, 1, 0, 0, 0, 0, time.FixedZone("BRT", -3*3600))},
+		{name: "Keeps a time in the UnixNano range", value: time.Date(2019, 10, 1, 12, 0, 0, 0, time.UTC)},
+	}
+
+	for index, scenario := range scenarios {
+		test.Run(
+			fmt.Sprintf("[%d]-%s", index, scenario.name),
+			func(t *testing.T) {
+				value := Time("timevalue", scenario.value)
+				assert.True(t, scenario.value.Equal(value.AsTime()), "time %s", value.AsTime())
+				assert.Equal(t, scenario.value.Location(), value.AsTime().Location(), "time location")
+				assert.Equal(t, scenario.value.IsZero(), value.AsTime().IsZero(), "zero time")
+			},
+		)
+	}
+}
+
+func TestValueAccessors(t *testing.T) {
+	timeNow := time.Now()
+	assert.Equal(t, "error", Err(nil).Name(), "error name")
+	assert.Nil(t, Err(nil).AsError(), "nil error")
+	assert.Equal(t, "string1", String("stringvalue", "string1").AsString())
+	assert.Equal(t, int64(-1), Int64("int64value", -1).AsInt64())
+	assert.Equal(t, uint64(1), Uint64("uint64value", 1).AsUint64())
+	assert.Equal(t, 0.5, Float64("float64value", 0.5).AsFloat64())
+	assert.True(t, Bool("boolvalue", true).AsBool())
+	assert.True(t, timeNow.Equal(Time("timevalue", timeNow).AsTime()))
+	assert.Equal(t, timeNow.Location(), Time("timevalue", timeNow).AsTime().Location())
+	assert.Equal(t, time.Minute, Duration("durationvalue", time.Minute).AsDuration())
+	assert.Nil(t, String("stringvalue", "string1").AsBytes())
+	assert.Nil(t, String("stringvalue", "string1").AsStrings())
+	assert.Equal(t, []Value{Int("status", 200)}, Group("http", Int("status", 200)).AsGroup())
+	assert.Nil(t, Group("http").AsGroup())
+	assert.Equal(t, "int64", Int64Kind.String())
+	assert.Equal(t, "group", GroupKind.String())
+	assert.Equal(t, "unknown", Kind(255).String())
+}
+
+type testUser struct {
+	id       string
+	password string
+}
+
+func (user testUser) LogValue() Value {
+	return Group("", String("id", user.id))
+}
+
+type testValuerChain int
+
+func (chain testValuerChain) LogValue() Value {
+	if chain == 0 {
+		return Int("depth", 0)
+	}
+	return NewValue("next", chain-1)
+}
+
+type testLoopValuer struct{}
+
+func (valuer testLoopValuer) LogValue() Value {
+	return NewValue("loop", valuer)
+}
+
+type testPanicValuer struct{}
+
+func (testPanicValuer) LogValue() Value {
+	panic("valuer panic")
+}
+
+type testResolve struct {
+	name     string
+	value    Value
+	expected Value
+}
+
+func TestValueResolve(test *testing.T) {
+	scenarios := []testResolve{
+		{
+			name:     "Does not resolve a typed Value",
+			value:    String("stringvalue", "string1"),
+			expected: String("stringvalue", "string1"),
+		},
+		{
+			name:     "Does not resolve an any Value",
+			value:    NewValue("anyvalue", 999),
+			expected: NewValue("anyvalue", 999),
+		},
+		{
+			name:     "Resolves a Lazy value",
+			value:    Lazy("lazyvalue", func() interface{} { return 999 }),
+			expected: NewValue("lazyvalue", 999),
+		},
+		{
+			name:     "Resolves a Lazy typed value",
+			value:    Lazy("lazyvalue", func() interface{} { return Int("ignored", 999) }),
+			expected: Int("lazyvalue", 999),
+		},
+		{
+			name:     "Resolves a nil Lazy function",
+			value:    Lazy("lazyvalue", nil),
+			expected: NewValue("lazyvalue", nil),
+		},
+		{
+			name:     "Resolves a LogValuer keeping its name",
+			value:    NewValue("user", testUser{id: "user1", password: "secret"}),
+			expected: Group("user", String("id", "user1")),
+		},
+		{
+			name:     "Resolves a Lazy LogValuer",
+			value:    Lazy("user", func() interface{} { return testUser{id: "user1"} }),
+			expected: Group("user", String("id", "user1")),
+		},
+		{
+			name:     "Resolves a LogValuer chain",
+			value:    NewValue("chain", testValuerChain(3)),
+			expected: Int("chain", 0),
+		},
+		{
+			name:     "Stops resolving a LogValuer loop",
+			value:    NewValue("loop", testLoopValuer{}),
+			expected: NewValue("loop", "err_log_value_too_deep{Depth=100}"),
+		},
+		{
+			name:     "Recovers a LogValuer panic",
+			value:    NewValue("panic", testPanicValuer{}),
+			expected: NewValue("panic", "err_log_value_panic{Panic=valuer panic}"),
+		},
+	}
+
+	for index, scenario := range scenarios {
+		test.Run(
+			fmt.Sprintf("[%d]-%s", index, scenario.name),
+			func(t *testing.T) {
+				assert.Equal(t, scenario.expected, scenario.value.Resolve(), "resolved value")
+			},
+		)
+	}
+}
+
+func TestLazyDisabledLevel(t *testing.T) {
+	var (
+		driver    = newMockDriver()
+		evaluated bool
+		log       = NewWithLevel(driver, NewAtomicLevel(INFO))
+	)
+	driver.On("Log", DEBUG, "debuglog").Return(nil)
+	log.Debug(nil, "debuglog", Lazy("dump", func() interface{} {
+		evaluated = true
+		return "dump"
+	}))
+	assert.False(t, evaluated, "lazy value evaluated")
+	driver.AssertNotCalled(t, "Log", DEBUG, "debuglog")
+}
+
+type testSplitWith struct {
+	name    string
+	pending []Value
+	values  []Value
+	encode  []Value
+	keep    []Value
+}
+
+func TestSplitWith(test *testing.T) {
+	var (
+		user  = NewValue("user", testUser{id: "user1"})
+		group = Group("request", String("id", "request1"), user)
+	)
+	scenarios := []testSplitWith{
+		{
+			name:   "Encodes values without lazy values",
+			values: []Value{String("key", "value"), Namespace("http")},
+			encode: []Value{String("key", "value"), Namespace("http")},
+		},
+		{
+			name:   "Keeps the values from the first LogValuer on",
+			values: []Value{String("key", "value"), user, Int("status", 200)},
+			encode: []Value{String("key", "value")},
+			keep:   []Value{user, Int("status", 200)},
+		},
+		{
+			name:   "Keeps a group holding a LogValuer",
+			values: []Value{group},
+			encode: []Value{},
+			keep:   []Value{group},
+		},
+		{
+			name:    "Keeps every value after the pending values",
+			pending: []Value{user},
+			values:  []Value{String("key", "value")},
+			keep:    []Value{user, String("key", "value")},
+		},
+	}
+
+	for index, scenario := range scenarios {
+		test.Run(
+			fmt.Sprintf("[%d]-%s", index, scenario.name),
+			func(t *testing.T) {
+				encode, keep := SplitWith(scenario.pending, scenario.values)
+				assert.Equal(t, scenario.encode, encode, "encoded values")
+				assert.Equal(t, scenario.keep, keep, "pending values")
+			},
+		)
+	}
+}
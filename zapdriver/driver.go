@@ -0,0 +1,328 @@
+// Package zapdriver is an l.Driver which writes through a zap logger,
+// NewLogger builds a zap logger writing to the destinations of an l.Out
+package zapdriver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/rjansen/l"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// zapTraceLevel is the zap level used to represent TRACE, one step below zapcore.DebugLevel
+const zapTraceLevel = zapcore.DebugLevel - 1
+
+func newZapLevel(level l.Level) (zapcore.Level, error) {
+	switch level {
+	case l.TRACE:
+		return zapTraceLevel, nil
+	case l.DEBUG:
+		return zapcore.DebugLevel, nil
+	case l.INFO:
+		return zapcore.InfoLevel, nil
+	case l.WARN:
+		return zapcore.WarnLevel, nil
+	case l.ERROR:
+		return zapcore.ErrorLevel, nil
+	case l.PANIC:
+		return zapcore.PanicLevel, nil
+	case l.FATAL:
+		return zapcore.FatalLevel, nil
+	default:
+		return zapcore.DebugLevel, fmt.Errorf("unrecognized level: %q", level)
+	}
+}
+
+// levelOfZap returns the Level of a zap level, DPanicLevel is PANIC
+func levelOfZap(level zapcore.Level) l.Level {
+	switch {
+	case level <= zapTraceLevel:
+		return l.TRACE
+	case level == zapcore.DebugLevel:
+		return l.DEBUG
+	case level == zapcore.InfoLevel:
+		return l.INFO
+	case level == zapcore.WarnLevel:
+		return l.WARN
+	case level == zapcore.ErrorLevel:
+		return l.ERROR
+	case level < zapcore.FatalLevel:
+		return l.PANIC
+	default:
+		return l.FATAL
+	}
+}
+
+// zapLevelEncoder is zapcore.LowercaseLevelEncoder aware of the TRACE level
+func zapLevelEncoder(level zapcore.Level, encoder zapcore.PrimitiveArrayEncoder) {
+	if level == zapTraceLevel {
+		encoder.AppendString(l.TRACE.String())
+		return
+	}
+	zapcore.LowercaseLevelEncoder(level, encoder)
+}
+
+type zapLogger interface {
+	Check(zapcore.Level, string) zapWriter
+	With(...zap.Field) zapLogger
+	Named(string) zapLogger
+	Sync() error
+	Close() error
+}
+
+type zapWriter interface {
+	Write(...zap.Field)
+}
+
+type zapLoggerDelegate struct {
+	*zap.Logger
+	closer io.Closer
+}
+
+// newZapLoggerDelegate keeps the core of a logger built by NewLogger to close it,
+// the options applied later to the logger may wrap the core
+func newZapLoggerDelegate(logger *zap.Logger) *zapLoggerDelegate {
+	closer, _ := logger.Core().(io.Closer)
+	return &zapLoggerDelegate{
+		Logger: logger,
+		closer: closer,
+	}
+}
+
+func (logger *zapLoggerDelegate) Check(level zapcore.Level, msg string) zapWriter {
+	entry := logger.Logger.Check(level, msg)
+	if entry != nil && level >= zapcore.DPanicLevel {
+		// the Logger panics or exits after every driver wrote, so the annotated entry is checked again
+		// against the core to drop the panic or exit zap would run inside this write
+		terminal := entry
+		if entry = logger.Core().Check(terminal.Entry, nil); entry != nil {
+			entry.ErrorOutput = terminal.ErrorOutput
+		}
+	}
+	// a nil *zapcore.CheckedEntry must become a nil zapWriter, otherwise disabled levels look enabled to the driver
+	if entry != nil {
+		return entry
+	}
+	return nil
+}
+
+func (logger *zapLoggerDelegate) With(fields ...zap.Field) zapLogger {
+	return &zapLoggerDelegate{Logger: logger.Logger.With(fields...), closer: logger.closer}
+}
+
+func (logger *zapLoggerDelegate) Named(name string) zapLogger {
+	return &zapLoggerDelegate{Logger: logger.Logger.Named(name), closer: logger.closer}
+}
+
+// Close closes the sinks opened by NewLogger, a zap logger built in any other way has nothing to close
+func (logger *zapLoggerDelegate) Close() error {
+	if logger.closer != nil {
+		return logger.closer.Close()
+	}
+	return nil
+}
+
+func newZapFields(values []l.Value) []zapcore.Field {
+	return appendZapFields(make([]zapcore.Field, 0, len(values)), values)
+}
+
+func appendZapFields(fields []zapcore.Field, values []l.Value) []zapcore.Field {
+	for _, logValue := range values {
+		logValue = logValue.Resolve()
+		if logValue.Kind() == l.GroupKind && logValue.Name() == "" {
+			fields = appendZapFields(fields, logValue.AsGroup())
+			continue
+		}
+		fields = append(fields, newZapField(logValue))
+	}
+	return fields
+}
+
+// zapGroup encodes the values of a group as a nested zap object
+type zapGroup []l.Value
+
+func (group zapGroup) MarshalLogObject(encoder zapcore.ObjectEncoder) error {
+	for _, field := range newZapFields(group) {
+		field.AddTo(encoder)
+	}
+	return nil
+}
+
+func newZapField(value l.Value) zapcore.Field {
+	switch value.Kind() {
+	case l.StringKind:
+		return zap.String(value.Name(), value.AsString())
+	case l.Int64Kind:
+		return zap.Int64(value.Name(), value.AsInt64())
+	case l.Uint64Kind:
+		return zap.Uint64(value.Name(), value.AsUint64())
+	case l.Float64Kind:
+		return zap.Float64(value.Name(), value.AsFloat64())
+	case l.BoolKind:
+		return zap.Bool(value.Name(), value.AsBool())
+	case l.TimeKind:
+		return zap.Time(value.Name(), value.AsTime())
+	case l.DurationKind:
+		return zap.Duration(value.Name(), value.AsDuration())
+	case l.ErrorKind:
+		return zap.NamedError(value.Name(), value.AsError())
+	case l.BytesKind:
+		return zap.Binary(value.Name(), value.AsBytes())
+	case l.StringsKind:
+		return zap.Strings(value.Name(), value.AsStrings())
+	case l.GroupKind:
+		if len(value.AsGroup()) == 0 {
+			return zap.Skip()
+		}
+		return zap.Object(value.Name(), zapGroup(value.AsGroup()))
+	case l.NamespaceKind:
+		return zap.Namespace(value.Name())
+	default:
+		return zap.Any(value.Name(), value.Any())
+	}
+}
+
+// newEntryFields converts the values kept pending by With followed by the values of the log call
+func newEntryFields(pending []l.Value, values []l.Value) []zapcore.Field {
+	if len(pending) == 0 {
+		return newZapFields(values)
+	}
+	fields := make([]zapcore.Field, 0, len(pending)+len(values))
+	return appendZapFields(appendZapFields(fields, pending), values)
+}
+
+type zapWriterDelegate struct {
+	zapWriter
+	pending []l.Value
+}
+
+func (writer *zapWriterDelegate) Write(values ...l.Value) {
+	writer.zapWriter.Write(newEntryFields(writer.pending, values)...)
+}
+
+// zapCallerWriterDelegate replaces the caller found by zap, which is a frame of this package, with the Logger call site
+type zapCallerWriterDelegate struct {
+	entry   *zapcore.CheckedEntry
+	pending []l.Value
+}
+
+func (writer *zapCallerWriterDelegate) Write(values ...l.Value) {
+	writer.entry.Write(newEntryFields(writer.pending, values)...)
+}
+
+func (writer *zapCallerWriterDelegate) WriteCaller(caller l.Caller, values ...l.Value) {
+	if caller.Defined() {
+		frame := caller.Frame()
+		writer.entry.Entry.Caller = zapcore.NewEntryCaller(frame.PC, frame.File, frame.Line, true)
+	}
+	writer.Write(values...)
+}
+
+type driver struct {
+	logger zapLogger
+	level  l.LevelController
+	// pending are the values of With from the first lazy value on, zap would resolve them when they are added
+	pending []l.Value
+}
+
+func newDriver(logger zapLogger) l.Driver {
+	return driver{
+		logger: logger,
+	}
+}
+
+// LevelController returns the threshold of a logger built by NewLogger, l.New uses it as the Logger threshold
+func (driver driver) LevelController() l.LevelController {
+	return driver.level
+}
+
+func (driver driver) Log(level l.Level, msg string) l.LogWriter {
+	zapLevel, levelErr := newZapLevel(level)
+	if levelErr != nil {
+		return nil
+	}
+	writer := driver.logger.Check(zapLevel, msg)
+	if writer == nil {
+		return nil
+	}
+	if entry, ok := writer.(*zapcore.CheckedEntry); ok && entry.Entry.Caller.Defined {
+		return &zapCallerWriterDelegate{
+			entry:   entry,
+			pending: driver.pending,
+		}
+	}
+	return &zapWriterDelegate{
+		zapWriter: writer,
+		pending:   driver.pending,
+	}
+}
+
+// With adds the values to the zap logger, the lazy values are kept unresolved and written on every entry
+func (driver driver) With(values ...l.Value) l.Driver {
+	values, driver.pending = l.SplitWith(driver.pending, values)
+	if len(values) > 0 {
+		driver.logger = driver.logger.With(newZapFields(values)...)
+	}
+	return driver
+}
+
+func (driver driver) Named(name string) l.Driver {
+	driver.logger = driver.logger.Named(name)
+	return driver
+}
+
+func (driver driver) Sync(context.Context) error {
+	return driver.logger.Sync()
+}
+
+// Close syncs the zap logger and closes the outputs opened by NewLogger
+func (driver driver) Close(context.Context) error {
+	return errors.Join(driver.logger.Sync(), driver.logger.Close())
+}
+
+// New creates a Driver which writes through an already configured zap logger, a nil logger writes nothing.
+// The zap options, like zap.AddCaller or zap.Hooks, are applied to the logger of the driver.
+// The driver of a logger built by NewLogger is an l.LevelDriver, l.New links the Logger threshold to the zap level
+func New(logger *zap.Logger, options ...zap.Option) l.Driver {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	delegate := newZapLoggerDelegate(logger)
+	if len(options) > 0 {
+		delegate.Logger = logger.WithOptions(options...)
+	}
+	return driver{
+		logger: delegate,
+		level:  LevelOf(logger),
+	}
+}
+
+// NewFromCore creates a Driver which writes through the zap core
+func NewFromCore(core zapcore.Core) l.Driver {
+	return New(zap.New(core))
+}
+
+// NewDriver creates a Driver which writes through the zap logger.
+//
+// Deprecated: NewDriver was l.NewDriver before the zap driver left the l package, use New
+func NewDriver(logger *zap.Logger) l.Driver {
+	return New(logger)
+}
+
+// NewZapDriver creates a Driver which writes through the zap logger with the zap options of WithZapOptions.
+//
+// Deprecated: NewZapDriver was l.NewZapDriver before the zap driver left the l package, use New
+func NewZapDriver(logger *zap.Logger, options ...Option) l.Driver {
+	return New(logger, newZapOptions(options).zapOptions...)
+}
+
+// NewZapDriverFromCore creates a Driver which writes through the zap core.
+//
+// Deprecated: NewZapDriverFromCore was l.NewZapDriverFromCore before the zap driver left the l package, use NewFromCore
+func NewZapDriverFromCore(core zapcore.Core) l.Driver {
+	return NewFromCore(core)
+}
@@ -0,0 +1,767 @@
+package zapdriver
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log/slog"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/rjansen/l"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+type testDriver struct {
+	name     string
+	logger   *mockZapLogger
+	writer   *mockZapWriter
+	level    l.Level
+	zapLevel zapcore.Level
+	message  string
+	values   []l.Value
+	fields   []zapcore.Field
+}
+
+func (scenario testDriver) setup(t *testing.T) {
+	if scenario.writer != nil {
+		scenario.writer.On("Write", mock.AnythingOfType("[]zapcore.Field")).Once()
+		scenario.logger.On("Check", mock.AnythingOfType("zapcore.Level"), mock.AnythingOfType("string")).Return(
+			scenario.writer,
+		).Once()
+	} else {
+		scenario.logger.On("Check", mock.AnythingOfType("zapcore.Level"), mock.AnythingOfType("string")).Return(
+			nil,
+		).Once()
+	}
+	scenario.logger.On("Sync").Return(nil).Once()
+	scenario.logger.On("Close").Return(nil).Once()
+}
+
+func TestDriver(test *testing.T) {
+	var (
+		timeNow = time.Now().UTC()
+		anyType = new(struct{})
+	)
+	scenarios := []testDriver{
+		{
+			name:    "Creates a new Driver and writes a debug log",
+			logger:  newMockZapLogger(),
+			writer:  newMockZapWriter(),
+			message: "debuglog",
+			level:   l.DEBUG,
+			values: []l.Value{
+				l.NewValue("stringvalue", "debug.stringvalue1"),
+				l.NewValue("intvalue", 999),
+				l.NewValue("intvalue32", int32(999)),
+				l.NewValue("intvalue64", int64(999)),
+				l.NewValue("floatvalue32", float32(999.99)),
+				l.NewValue("floatvalue", 999.99),
+				l.NewValue("timevalue", timeNow),
+				l.NewValue("durationvalue", time.Second*9),
+				l.NewValue("anyvalue", anyType),
+			},
+			zapLevel: zapcore.DebugLevel,
+			fields: []zapcore.Field{
+				zap.String("stringvalue", "debug.stringvalue1"),
+				zap.Int("intvalue", 999),
+				zap.Int32("intvalue32", int32(999)),
+				zap.Int64("intvalue64", int64(999)),
+				zap.Float32("floatvalue32", float32(999.99)),
+				zap.Float64("floatvalue", 999.99),
+				zap.Time("timevalue", timeNow),
+				zap.Duration("durationvalue", time.Second*9),
+				zap.Reflect("anyvalue", anyType),
+			},
+		},
+		{
+			name:    "Creates a new Driver and writes a info log",
+			logger:  newMockZapLogger(),
+			writer:  newMockZapWriter(),
+			level:   l.INFO,
+			message: "infolog",
+			values: []l.Value{
+				l.NewValue("stringvalue", "info.stringvalue1"),
+				l.NewValue("intvalue", 999),
+				l.NewValue("floatvalue", 999.99),
+				l.NewValue("timevalue", timeNow),
+				l.NewValue("durationvalue", time.Second*9),
+				l.NewValue("anyvalue", anyType),
+			},
+			zapLevel: zapcore.InfoLevel,
+			fields: []zapcore.Field{
+				zap.String("stringvalue", "info.stringvalue1"),
+				zap.Int64("intvalue", 999),
+				zap.Float64("floatvalue", 999.99),
+				zap.Time("timevalue", timeNow),
+				zap.Duration("durationvalue", time.Second*9),
+				zap.Reflect("anyvalue", anyType),
+			},
+		},
+		{
+			name:    "Creates a new Driver and writes a error log",
+			logger:  newMockZapLogger(),
+			writer:  newMockZapWriter(),
+			level:   l.ERROR,
+			message: "errorlog",
+			values: []l.Value{
+				l.NewValue("stringvalue", "error.stringvalue1"),
+				l.NewValue("intvalue", 999),
+				l.NewValue("floatvalue", 999.99),
+				l.NewValue("timevalue", timeNow),
+				l.NewValue("durationvalue", time.Second*9),
+				l.NewValue("errorvalue", errors.New("errorlog")),
+				l.NewValue("anyvalue", anyType),
+			},
+			zapLevel: zapcore.ErrorLevel,
+			fields: []zapcore.Field{
+				zap.String("stringvalue", "error.stringvalue1"),
+				zap.Int64("intvalue", 999),
+				zap.Float64("floatvalue", 999.99),
+				zap.Time("timevalue", timeNow),
+				zap.Duration("durationvalue", time.Second*9),
+				zap.NamedError("errorvalue", errors.New("errorlog")),
+				zap.Reflect("anyvalue", anyType),
+			},
+		},
+		{
+			name:    "Creates a new Driver and writes a typed values log",
+			logger:  newMockZapLogger(),
+			writer:  newMockZapWriter(),
+			message: "typedlog",
+			level:   l.INFO,
+			values: []l.Value{
+				l.String("stringvalue", "typed.stringvalue1"),
+				l.Int("intvalue", 999),
+				l.Int64("intvalue64", int64(999)),
+				l.Uint64("uintvalue64", uint64(999)),
+				l.Float64("floatvalue", 999.99),
+				l.Bool("boolvalue", true),
+				l.Time("timevalue", timeNow),
+				l.Duration("durationvalue", time.Second*9),
+				l.NamedErr("errorvalue", errors.New("errorlog")),
+				l.Err(errors.New("errorlog")),
+				l.Bytes("bytesvalue", []byte("bytes1")),
+				l.Strings("stringsvalue", []string{"a", "b"}),
+				l.Any("anyvalue", anyType),
+			},
+			zapLevel: zapcore.InfoLevel,
+			fields: []zapcore.Field{
+				zap.String("stringvalue", "typed.stringvalue1"),
+				zap.Int64("intvalue", 999),
+				zap.Int64("intvalue64", int64(999)),
+				zap.Uint64("uintvalue64", uint64(999)),
+				zap.Float64("floatvalue", 999.99),
+				zap.Bool("boolvalue", true),
+				zap.Time("timevalue", timeNow),
+				zap.Duration("durationvalue", time.Second*9),
+				zap.NamedError("errorvalue", errors.New("errorlog")),
+				zap.Error(errors.New("errorlog")),
+				zap.Binary("bytesvalue", []byte("bytes1")),
+				zap.Strings("stringsvalue", []string{"a", "b"}),
+				zap.Reflect("anyvalue", anyType),
+			},
+		},
+		{
+			name:    "Creates a new Driver and writes a trace log",
+			logger:  newMockZapLogger(),
+			writer:  newMockZapWriter(),
+			level:   l.TRACE,
+			message: "tracelog",
+			values: []l.Value{
+				l.NewValue("stringvalue", "trace.stringvalue1"),
+			},
+			zapLevel: zapTraceLevel,
+			fields: []zapcore.Field{
+				zap.String("stringvalue", "trace.stringvalue1"),
+			},
+		},
+		{
+			name:    "Creates a new Driver and writes a warn log",
+			logger:  newMockZapLogger(),
+			writer:  newMockZapWriter(),
+			level:   l.WARN,
+			message: "warnlog",
+			values: []l.Value{
+				l.NewValue("stringvalue", "warn.stringvalue1"),
+			},
+			zapLevel: zapcore.WarnLevel,
+			fields: []zapcore.Field{
+				zap.String("stringvalue", "warn.stringvalue1"),
+			},
+		},
+		{
+			name:    "Creates a new Driver but does not provide any writer to log message",
+			logger:  newMockZapLogger(),
+			writer:  nil,
+			level:   l.DEBUG,
+			message: "debugdisabledlog",
+			values: []l.Value{
+				l.NewValue("stringvalue", "debugdisabled.stringvalue1"),
+			},
+			zapLevel: zapcore.DebugLevel,
+			fields: []zapcore.Field{
+				zap.String("stringvalue", "debugdisabled.stringvalue1"),
+			},
+		},
+		{
+			name:    "Creates a new Driver but does not provide any writer to an invalid level",
+			logger:  newMockZapLogger(),
+			writer:  nil,
+			level:   l.Level("invalid"),
+			message: "invalidlog",
+			values: []l.Value{
+				l.NewValue("stringvalue", "debugdisabled.stringvalue1"),
+			},
+			zapLevel: zapcore.DebugLevel,
+			fields: []zapcore.Field{
+				zap.String("stringvalue", "debugdisabled.stringvalue1"),
+			},
+		},
+	}
+
+	for index, scenario := range scenarios {
+		test.Run(
+			fmt.Sprintf("[%d]-%s", index, scenario.name),
+			func(t *testing.T) {
+				scenario.setup(t)
+
+				var driver l.Driver = newDriver(scenario.logger)
+				assert.NotNil(t, driver, "driver instance")
+
+				writer := driver.Log(scenario.level, scenario.message)
+				if _, err := newZapLevel(scenario.level); err == nil {
+					scenario.logger.AssertCalled(t, "Check", scenario.zapLevel, scenario.message)
+				} else {
+					scenario.logger.AssertNotCalled(t, "Check", scenario.zapLevel, scenario.message)
+				}
+				if scenario.writer != nil {
+					assert.NotNil(t, writer, "writer instance")
+					writer.Write(scenario.values...)
+					scenario.writer.AssertCalled(t, "Write", scenario.fields)
+				}
+				assert.NoError(t, driver.Close(context.Background()), "close driver")
+				scenario.logger.AssertCalled(t, "Sync")
+				scenario.logger.AssertCalled(t, "Close")
+			},
+		)
+	}
+}
+
+type testZapLoggerDelegate struct {
+	name    string
+	zapMock *testZapMock
+	level   zapcore.Level
+	message string
+	fields  []zapcore.Field
+}
+
+type testZapMock struct {
+	logger   *zap.Logger
+	core     zapcore.Core
+	observer *observer.ObservedLogs
+}
+
+func newTestZapMock(level zapcore.Level) *testZapMock {
+	core, observer := observer.New(level)
+	return &testZapMock{
+		logger:   zap.New(core),
+		core:     core,
+		observer: observer,
+	}
+}
+
+func (scenario testZapLoggerDelegate) setup(t *testing.T) {
+}
+
+func TestZapLoggerDelegate(test *testing.T) {
+	scenarios := []testZapLoggerDelegate{
+		{
+			name:    "Creates a new zapLogger and writes a debug log",
+			zapMock: newTestZapMock(zapcore.DebugLevel),
+			level:   zapcore.DebugLevel,
+			message: "debuglog",
+			fields: []zapcore.Field{
+				zap.String("stringfield", "debug.stringvalue1"),
+				zap.Int64("intfield", 777),
+				zap.Float64("floatfield", 777.77),
+				zap.Reflect("reflectfield", new(struct{})),
+				zap.Time("timefield", time.Now().UTC()),
+				zap.Duration("durationfield", time.Second*7),
+				zap.NamedError("errorfield", errors.New("errorlog")),
+			},
+		},
+		{
+			name:    "Creates a new zapLogger and writes a info log",
+			zapMock: newTestZapMock(zapcore.InfoLevel),
+			level:   zapcore.InfoLevel,
+			message: "infolog",
+			fields: []zapcore.Field{
+				zap.String("stringfield", "info.stringvalue1"),
+				zap.Int64("intfield", 888),
+				zap.Float64("floatfield", 888.88),
+				zap.Reflect("reflectfield", new(struct{})),
+				zap.Time("timefield", time.Now().UTC()),
+				zap.Duration("durationfield", time.Second*8),
+				zap.NamedError("errorfield", errors.New("errorlog")),
+			},
+		},
+		{
+			name:    "Creates a new zapLogger and writes a error log",
+			zapMock: newTestZapMock(zapcore.ErrorLevel),
+			level:   zapcore.ErrorLevel,
+			message: "errorlog",
+			fields: []zapcore.Field{
+				zap.String("stringfield", "error.stringvalue1"),
+				zap.Int64("intfield", 666),
+				zap.Float64("floatfield", 666.66),
+				zap.Reflect("reflectfield", new(struct{})),
+				zap.Time("timefield", time.Now().UTC()),
+				zap.Duration("durationfield", time.Second*6),
+				zap.NamedError("errorfield", errors.New("errorlog")),
+			},
+		},
+	}
+
+	for index, scenario := range scenarios {
+		test.Run(
+			fmt.Sprintf("[%d]-%s", index, scenario.name),
+			func(t *testing.T) {
+				scenario.setup(t)
+
+				logger := newZapLoggerDelegate(scenario.zapMock.logger)
+				assert.NotNil(t, logger, "delegateLogger instance")
+
+				writer := logger.Check(scenario.level, scenario.message)
+				assert.NotNil(t, writer, "writer instance")
+				writer.Write(scenario.fields...)
+				observedLogs := scenario.zapMock.observer.All()
+				assert.NotEmpty(t, observedLogs, "observed logs")
+				for _, logEntry := range observedLogs {
+					assert.Equal(t, scenario.level, logEntry.Level, "log level")
+					assert.Equal(t, scenario.message, logEntry.Message, "log message")
+					assert.Equal(t, scenario.fields, logEntry.Context)
+				}
+				assert.NoError(t, logger.Sync(), "deleagteLogger sync")
+			},
+		)
+	}
+}
+
+func TestZapDriverRuntimeLevel(t *testing.T) {
+	var (
+		zapMock = newTestZapMock(zapTraceLevel)
+		log     = l.NewWithLevel(newDriver(newZapLoggerDelegate(zapMock.logger)), l.NewAtomicLevel(l.INFO))
+	)
+	log.Debug(context.Background(), "debuglog")
+	assert.Equal(t, 0, zapMock.observer.Len(), "observed logs")
+
+	assert.NoError(t, log.SetLevel(l.TRACE), "SetLevel error")
+	log.Trace(context.Background(), "tracelog")
+	log.Named("db").Debug(context.Background(), "debuglog")
+	assert.Equal(t, 2, zapMock.observer.Len(), "observed logs")
+
+	assert.NoError(t, log.SetLevel(l.ERROR), "SetLevel error")
+	log.Warn(context.Background(), "warnlog")
+	assert.Equal(t, 2, zapMock.observer.Len(), "observed logs")
+}
+
+func TestZapDriverChild(t *testing.T) {
+	var (
+		zapMock = newTestZapMock(zapcore.DebugLevel)
+		driver  = newDriver(newZapLoggerDelegate(zapMock.logger))
+		log     = l.New(driver).Named("api").With(l.NewValue("component", "api")).Named("db").Named("pool")
+	)
+	log.Info(context.Background(), "infolog", l.NewValue("key", "value"))
+
+	observedLogs := zapMock.observer.All()
+	assert.Len(t, observedLogs, 1, "observed logs")
+	assert.Equal(t, "api.db.pool", observedLogs[0].LoggerName, "logger name")
+	assert.Equal(t, "infolog", observedLogs[0].Message, "log message")
+	assert.Equal(t,
+		[]zapcore.Field{zap.String("component", "api"), zap.String("key", "value")},
+		observedLogs[0].Context,
+		"log context",
+	)
+}
+
+type testZapLevel struct {
+	name     string
+	level    l.Level
+	zapLevel zapcore.Level
+	encoded  string
+	err      error
+}
+
+func TestZapLevel(test *testing.T) {
+	scenarios := []testZapLevel{
+		{name: "Maps the trace level", level: l.TRACE, zapLevel: zapTraceLevel, encoded: "trace"},
+		{name: "Maps the debug level", level: l.DEBUG, zapLevel: zapcore.DebugLevel, encoded: "debug"},
+		{name: "Maps the info level", level: l.INFO, zapLevel: zapcore.InfoLevel, encoded: "info"},
+		{name: "Maps the warn level", level: l.WARN, zapLevel: zapcore.WarnLevel, encoded: "warn"},
+		{name: "Maps the error level", level: l.ERROR, zapLevel: zapcore.ErrorLevel, encoded: "error"},
+		{name: "Maps the panic level", level: l.PANIC, zapLevel: zapcore.PanicLevel, encoded: "panic"},
+		{name: "Maps the fatal level", level: l.FATAL, zapLevel: zapcore.FatalLevel, encoded: "fatal"},
+		{
+			name:     "Does not map an invalid level",
+			level:    l.Level("invalid"),
+			zapLevel: zapcore.DebugLevel,
+			err:      errors.New("unrecognized level: \"invalid\""),
+		},
+	}
+
+	for index, scenario := range scenarios {
+		test.Run(
+			fmt.Sprintf("[%d]-%s", index, scenario.name),
+			func(t *testing.T) {
+				zapLevel, err := newZapLevel(scenario.level)
+				assert.Equal(t, scenario.err, err, "error instance")
+				assert.Equal(t, scenario.zapLevel, zapLevel, "zap level")
+				if scenario.err == nil {
+					encoder := zapcore.NewMapObjectEncoder()
+					_ = encoder.AddArray("level", zapcore.ArrayMarshalerFunc(
+						func(array zapcore.ArrayEncoder) error {
+							zapLevelEncoder(zapLevel, array)
+							return nil
+						},
+					))
+					assert.Equal(t, []interface{}{scenario.encoded}, encoder.Fields["level"], "encoded level")
+				}
+			},
+		)
+	}
+}
+
+func newBenchmarkZapLogger() l.Logger {
+	core := zapcore.NewCore(
+		zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig()),
+		zapcore.AddSync(ioutil.Discard),
+		zapcore.DebugLevel,
+	)
+	return l.New(newDriver(newZapLoggerDelegate(zap.New(core))))
+}
+
+func BenchmarkZapDriverAnyValues(b *testing.B) {
+	var (
+		log     = newBenchmarkZapLogger()
+		ctx     = context.Background()
+		timeNow = time.Now()
+	)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for index := 0; index < b.N; index++ {
+		log.Info(ctx, "benchmark",
+			l.NewValue("string", "value"),
+			l.NewValue("int", index),
+			l.NewValue("float", 999.99),
+			l.NewValue("bool", true),
+			l.NewValue("time", timeNow),
+			l.NewValue("duration", time.Second),
+		)
+	}
+}
+
+func BenchmarkZapDriverTypedValues(b *testing.B) {
+	var (
+		log     = newBenchmarkZapLogger()
+		ctx     = context.Background()
+		timeNow = time.Now()
+	)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for index := 0; index < b.N; index++ {
+		log.Info(ctx, "benchmark",
+			l.String("string", "value"),
+			l.Int("int", index),
+			l.Float64("float", 999.99),
+			l.Bool("bool", true),
+			l.Time("time", timeNow),
+			l.Duration("duration", time.Second),
+		)
+	}
+}
+
+type testZapGroup struct {
+	name     string
+	with     []l.Value
+	values   []l.Value
+	expected string
+}
+
+func TestZapDriverGroup(test *testing.T) {
+	scenarios := []testZapGroup{
+		{
+			name: "Writes a nested group",
+			values: []l.Value{
+				l.Group("http", l.String("method", "GET"), l.Int("status", 200)),
+			},
+			expected: `{"level":"info","message":"grouplog","http":{"method":"GET","status":200}}`,
+		},
+		{
+			name: "Writes a group inside a group",
+			values: []l.Value{
+				l.Group("http", l.String("method", "GET"), l.Group("response", l.Int("status", 200), l.Bool("cached", true))),
+			},
+			expected: `{"level":"info","message":"grouplog","http":{"method":"GET","response":{"status":200,"cached":true}}}`,
+		},
+		{
+			name: "Drops an empty group and inlines an unnamed group",
+			values: []l.Value{
+				l.Group("empty"),
+				l.Group("", l.String("method", "GET"), l.Int("status", 200)),
+			},
+			expected: `{"level":"info","message":"grouplog","method":"GET","status":200}`,
+		},
+		{
+			name: "Writes a namespace on a log call",
+			values: []l.Value{
+				l.String("id", "request1"),
+				l.Namespace("http"),
+				l.String("method", "GET"),
+				l.Int("status", 200),
+			},
+			expected: `{"level":"info","message":"grouplog","id":"request1","http":{"method":"GET","status":200}}`,
+		},
+		{
+			name: "Writes a namespace on With",
+			with: []l.Value{
+				l.String("id", "request1"),
+				l.Namespace("http"),
+				l.String("method", "GET"),
+			},
+			values: []l.Value{
+				l.Int("status", 200),
+			},
+			expected: `{"level":"info","message":"grouplog","id":"request1","http":{"method":"GET","status":200}}`,
+		},
+	}
+
+	for index, scenario := range scenarios {
+		test.Run(
+			fmt.Sprintf("[%d]-%s", index, scenario.name),
+			func(t *testing.T) {
+				var (
+					buffer = new(bytes.Buffer)
+					config = zapcore.EncoderConfig{
+						LevelKey:    "level",
+						MessageKey:  "message",
+						EncodeLevel: zapLevelEncoder,
+					}
+					core = zapcore.NewCore(zapcore.NewJSONEncoder(config), zapcore.AddSync(buffer), zapcore.DebugLevel)
+					log  = l.New(newDriver(newZapLoggerDelegate(zap.New(core))))
+				)
+				log.With(scenario.with...).Info(context.Background(), "grouplog", scenario.values...)
+				assert.JSONEq(t, scenario.expected, buffer.String(), "log entry")
+			},
+		)
+	}
+}
+
+func TestZapDriverLazy(t *testing.T) {
+	var (
+		zapMock   = newTestZapMock(zapcore.InfoLevel)
+		log       = l.New(newDriver(newZapLoggerDelegate(zapMock.logger)))
+		evaluated int
+		lazy      = l.Lazy("dump", func() interface{} {
+			evaluated++
+			return "dump1"
+		})
+	)
+	log.Debug(context.Background(), "debuglog", lazy)
+	assert.Equal(t, 0, evaluated, "lazy evaluations")
+
+	log.Info(context.Background(), "infolog", lazy, l.NewValue("user", testUser{id: "user1", password: "secret"}))
+	assert.Equal(t, 1, evaluated, "lazy evaluations")
+	observedLogs := zapMock.observer.All()
+	assert.Len(t, observedLogs, 1, "observed logs")
+	assert.Equal(t,
+		map[string]interface{}{"dump": "dump1", "user": map[string]interface{}{"id": "user1"}},
+		observedLogs[0].ContextMap(),
+		"log context",
+	)
+
+	child := log.With(lazy, l.String("key", "value"))
+	assert.Equal(t, 1, evaluated, "lazy evaluations")
+	child.Debug(context.Background(), "debuglog")
+	child.Info(context.Background(), "infolog")
+	assert.Equal(t, 2, evaluated, "lazy evaluations")
+	observedLogs = zapMock.observer.All()
+	assert.Len(t, observedLogs, 2, "observed logs")
+	assert.Equal(t,
+		[]zapcore.Field{zap.String("dump", "dump1"), zap.String("key", "value")},
+		observedLogs[1].Context,
+		"log context",
+	)
+}
+
+func TestNew(t *testing.T) {
+	var (
+		zapMock = newTestZapMock(zapcore.DebugLevel)
+		hooked  int
+		driver  = New(zapMock.logger,
+			zap.Hooks(func(zapcore.Entry) error {
+				hooked++
+				return nil
+			}),
+			zap.Fields(zap.String("service", "l")),
+		)
+		log = l.New(driver)
+	)
+	log.Info(context.Background(), "infolog", l.String("key", "value"))
+
+	observedLogs := zapMock.observer.All()
+	assert.Len(t, observedLogs, 1, "observed logs")
+	assert.Equal(t,
+		[]zapcore.Field{zap.String("service", "l"), zap.String("key", "value")},
+		observedLogs[0].Context,
+		"log context",
+	)
+	assert.Equal(t, 1, hooked, "hook calls")
+}
+
+func TestNewFromCore(t *testing.T) {
+	var (
+		core, observer = observer.New(zapcore.InfoLevel)
+		log            = l.New(NewFromCore(core))
+	)
+	log.Debug(context.Background(), "debuglog")
+	log.Info(context.Background(), "infolog")
+	assert.Equal(t, 1, observer.Len(), "observed logs")
+	assert.Equal(t, "infolog", observer.All()[0].Message, "log message")
+}
+
+func TestDeprecatedDrivers(t *testing.T) {
+	var (
+		zapMock = newTestZapMock(zapcore.DebugLevel)
+		core, _ = observer.New(zapcore.InfoLevel)
+	)
+	l.New(NewZapDriver(zapMock.logger, WithZapOptions(zap.Fields(zap.String("service", "l"))))).
+		Info(context.Background(), "infolog")
+	l.New(NewDriver(zapMock.logger)).Info(context.Background(), "infolog")
+
+	observedLogs := zapMock.observer.All()
+	assert.Len(t, observedLogs, 2, "observed logs")
+	assert.Equal(t, []zapcore.Field{zap.String("service", "l")}, observedLogs[0].Context, "zap options context")
+	assert.Empty(t, observedLogs[1].Context, "driver context")
+	assert.NotNil(t, NewZapDriverFromCore(core).Log(l.INFO, "infolog"), "core writer")
+}
+
+func TestNewNil(t *testing.T) {
+	driver := New(nil)
+	assert.NotNil(t, driver, "driver instance")
+	assert.Nil(t, driver.Log(l.ERROR, "errorlog"), "writer instance")
+	assert.NoError(t, driver.Close(context.Background()), "close driver")
+}
+
+func TestZapDriverCloseError(t *testing.T) {
+	var (
+		logger   = newMockZapLogger()
+		driver   = newDriver(logger)
+		errSync  = errors.New("err_sync")
+		errClose = errors.New("err_close")
+	)
+	logger.On("Sync").Return(errSync).Twice()
+	logger.On("Close").Return(errClose).Once()
+
+	assert.Equal(t, errSync, driver.Sync(context.Background()), "sync error")
+	err := driver.Close(context.Background())
+	assert.True(t, errors.Is(err, errSync), "close sync error")
+	assert.True(t, errors.Is(err, errClose), "close error")
+	logger.AssertExpectations(t)
+}
+
+func TestZapDriverTee(t *testing.T) {
+	var (
+		core, observer = observer.New(zapcore.DebugLevel)
+		buffer         = new(bytes.Buffer)
+		log            = l.New(l.NewTeeDriver(
+			New(zap.New(core, zap.AddCaller(), zap.AddStacktrace(zapcore.ErrorLevel))),
+			l.NewSlogDriver(slog.NewJSONHandler(buffer, nil)),
+		))
+	)
+	assert.PanicsWithValue(t, "paniclog", func() {
+		log.Panic(context.Background(), "paniclog", l.String("key", "value"))
+	}, "logger panic")
+
+	observedLogs := observer.All()
+	assert.Len(t, observedLogs, 1, "observed logs")
+	assert.Equal(t, zapcore.PanicLevel, observedLogs[0].Level, "zap level")
+	assert.True(t, observedLogs[0].Caller.Defined, "zap caller")
+	assert.NotEmpty(t, observedLogs[0].Stack, "zap stack")
+	assert.Contains(t, buffer.String(), `"msg":"paniclog"`, "slog entry")
+
+	New(zap.New(core)).Log(l.FATAL, "fatallog").Write()
+	assert.Equal(t, 2, observer.Len(), "observed logs")
+}
+
+type testUser struct {
+	id       string
+	password string
+}
+
+func (user testUser) LogValue() l.Value {
+	return l.Group("", l.String("id", user.id))
+}
+
+// currentLine returns the line of its call site
+func currentLine() int {
+	_, _, line, _ := runtime.Caller(1)
+	return line
+}
+
+type testCaller struct {
+	name string
+	log  func(l.Logger) int
+}
+
+func TestCaller(test *testing.T) {
+	scenarios := []testCaller{
+		{
+			name: "Reports the Logger method call site to zap",
+			log: func(log l.Logger) int {
+				line := currentLine() + 1
+				log.Debug(context.Background(), "debuglog")
+				return line
+			},
+		},
+		{
+			name: "Reports the package level function call site to zap",
+			log: func(log l.Logger) int {
+				_ = l.SetLoggerDefault(log)
+				line := currentLine() + 1
+				l.Info(context.Background(), "infolog")
+				return line
+			},
+		},
+	}
+
+	defaultLogger := l.LoggerDefault
+	defer l.SetLoggerDefault(defaultLogger)
+
+	for index, scenario := range scenarios {
+		test.Run(
+			fmt.Sprintf("[%d]-%s", index, scenario.name),
+			func(t *testing.T) {
+				var (
+					core, observer = observer.New(zapcore.DebugLevel)
+					log            = l.New(New(zap.New(core), zap.AddCaller()))
+					line           = scenario.log(log)
+				)
+				observedLogs := observer.All()
+				assert.Len(t, observedLogs, 1, "observed logs")
+				caller := observedLogs[0].Caller
+				assert.True(t, caller.Defined, "caller defined")
+				assert.Equal(t, "driver_test.go", filepath.Base(caller.File), "caller file")
+				assert.Equal(t, line, caller.Line, "caller line")
+			},
+		)
+	}
+}
@@ -0,0 +1,463 @@
+package zapdriver
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rjansen/l"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Option customizes the zap logger built by NewLogger, the driver of New takes zap options instead
+type Option func(*zapOptions)
+
+const (
+	// ISO8601Time formats times like 2006-01-02T15:04:05.000Z0700, it is the default time format
+	ISO8601Time TimeFormat = "iso8601"
+	// RFC3339NanoTime formats times with time.RFC3339Nano
+	RFC3339NanoTime TimeFormat = "rfc3339nano"
+	// EpochTime formats times as floating point seconds since the unix epoch
+	EpochTime TimeFormat = "epoch"
+	// EpochMillisTime formats times as floating point milliseconds since the unix epoch
+	EpochMillisTime TimeFormat = "epochmillis"
+	// EpochNanosTime formats times as integer nanoseconds since the unix epoch
+	EpochNanosTime TimeFormat = "epochnanos"
+)
+
+// TimeFormat is the format of the entry time written by the zap logger
+type TimeFormat string
+
+type zapOptions struct {
+	zapOptions      []zap.Option
+	encoding        l.Encoding
+	timeKey         string
+	levelKey        string
+	nameKey         string
+	messageKey      string
+	callerKey       string
+	stacktraceKey   string
+	timeFormat      TimeFormat
+	caller          bool
+	stacktraceLevel l.Level
+}
+
+func newZapOptions(options []Option) zapOptions {
+	zapOptions := zapOptions{
+		encoding:        l.JSONEncoding,
+		timeKey:         "time",
+		levelKey:        "level",
+		nameKey:         "logger",
+		messageKey:      "message",
+		callerKey:       "caller",
+		stacktraceKey:   "stack",
+		timeFormat:      ISO8601Time,
+		stacktraceLevel: l.ERROR,
+	}
+	for _, option := range options {
+		option(&zapOptions)
+	}
+	return zapOptions
+}
+
+// WithZapOptions appends zap options, like hooks or sampling, to the zap logger built by NewLogger
+func WithZapOptions(options ...zap.Option) Option {
+	return func(zapOptions *zapOptions) {
+		zapOptions.zapOptions = append(zapOptions.zapOptions, options...)
+	}
+}
+
+// WithEncoding sets the entry format of NewLogger, a destination of the output may replace it
+func WithEncoding(encoding l.Encoding) Option {
+	return func(zapOptions *zapOptions) {
+		zapOptions.encoding = encoding
+	}
+}
+
+// WithTimeKey renames the time key of NewLogger entries, an empty key omits the time
+func WithTimeKey(key string) Option {
+	return func(zapOptions *zapOptions) {
+		zapOptions.timeKey = key
+	}
+}
+
+// WithLevelKey renames the level key of NewLogger entries, an empty key omits the level
+func WithLevelKey(key string) Option {
+	return func(zapOptions *zapOptions) {
+		zapOptions.levelKey = key
+	}
+}
+
+// WithNameKey renames the logger name key of NewLogger entries, an empty key omits the name
+func WithNameKey(key string) Option {
+	return func(zapOptions *zapOptions) {
+		zapOptions.nameKey = key
+	}
+}
+
+// WithMessageKey renames the message key of NewLogger entries, an empty key omits the message
+func WithMessageKey(key string) Option {
+	return func(zapOptions *zapOptions) {
+		zapOptions.messageKey = key
+	}
+}
+
+// WithCallerKey renames the caller key of NewLogger entries written when WithCaller is enabled
+func WithCallerKey(key string) Option {
+	return func(zapOptions *zapOptions) {
+		zapOptions.callerKey = key
+	}
+}
+
+// WithStacktraceKey renames the stacktrace key of NewLogger entries
+func WithStacktraceKey(key string) Option {
+	return func(zapOptions *zapOptions) {
+		zapOptions.stacktraceKey = key
+	}
+}
+
+// WithTimeFormat sets the entry time format of NewLogger
+func WithTimeFormat(format TimeFormat) Option {
+	return func(zapOptions *zapOptions) {
+		zapOptions.timeFormat = format
+	}
+}
+
+// WithCaller enables the caller file and line on NewLogger entries, it is disabled by default
+func WithCaller(enabled bool) Option {
+	return func(zapOptions *zapOptions) {
+		zapOptions.caller = enabled
+	}
+}
+
+// WithStacktraceLevel sets the level from which NewLogger entries carry a stacktrace, ERROR by default
+func WithStacktraceLevel(level l.Level) Option {
+	return func(zapOptions *zapOptions) {
+		zapOptions.stacktraceLevel = level
+	}
+}
+
+func newZapTimeEncoder(format TimeFormat) (zapcore.TimeEncoder, error) {
+	switch format {
+	case ISO8601Time:
+		return zapcore.ISO8601TimeEncoder, nil
+	case RFC3339NanoTime:
+		return func(t time.Time, encoder zapcore.PrimitiveArrayEncoder) {
+			encoder.AppendString(t.Format(time.RFC3339Nano))
+		}, nil
+	case EpochTime:
+		return zapcore.EpochTimeEncoder, nil
+	case EpochMillisTime:
+		return zapcore.EpochMillisTimeEncoder, nil
+	case EpochNanosTime:
+		return zapcore.EpochNanosTimeEncoder, nil
+	default:
+		return nil, fmt.Errorf("err_invalid_time_format{TimeFormat=%q}", format)
+	}
+}
+
+func (zapOptions zapOptions) encoderConfig() (zapcore.EncoderConfig, error) {
+	timeEncoder, err := newZapTimeEncoder(zapOptions.timeFormat)
+	if err != nil {
+		return zapcore.EncoderConfig{}, err
+	}
+	callerKey := ""
+	if zapOptions.caller {
+		callerKey = zapOptions.callerKey
+	}
+	return zapcore.EncoderConfig{
+		TimeKey:        zapOptions.timeKey,
+		LevelKey:       zapOptions.levelKey,
+		NameKey:        zapOptions.nameKey,
+		MessageKey:     zapOptions.messageKey,
+		CallerKey:      callerKey,
+		StacktraceKey:  zapOptions.stacktraceKey,
+		LineEnding:     zapcore.DefaultLineEnding,
+		EncodeLevel:    zapLevelEncoder,
+		EncodeTime:     timeEncoder,
+		EncodeDuration: zapcore.StringDurationEncoder,
+		EncodeCaller:   zapcore.ShortCallerEncoder,
+	}, nil
+}
+
+func newZapEncoder(encoding l.Encoding, config zapcore.EncoderConfig) (zapcore.Encoder, error) {
+	switch encoding {
+	case l.JSONEncoding:
+		return zapcore.NewJSONEncoder(config), nil
+	case l.ConsoleEncoding:
+		return zapcore.NewConsoleEncoder(config), nil
+	default:
+		return nil, fmt.Errorf("err_invalid_encoding{Encoding=%q}", encoding)
+	}
+}
+
+// zapSinks holds the opened sinks of the destinations to close them with the logger
+type zapSinks struct {
+	closers []func() error
+	once    sync.Once
+}
+
+// zapSinksCore is the core of a zap logger built by NewLogger, it closes the sinks of the logger and its children
+// and keeps the level of their cores
+type zapSinksCore struct {
+	zapcore.Core
+	sinks *zapSinks
+	level zap.AtomicLevel
+}
+
+func (core zapSinksCore) With(fields []zapcore.Field) zapcore.Core {
+	return zapSinksCore{Core: core.Core.With(fields), sinks: core.sinks, level: core.level}
+}
+
+func (core zapSinksCore) Close() error {
+	return core.sinks.close()
+}
+
+// zapOutputCore is the core of zapcore.NewCore which counts the entries on l.Stats
+// and reports the failures to the l.ErrorHandler instead of the zap error output
+type zapOutputCore struct {
+	zapcore.LevelEnabler
+	encoder zapcore.Encoder
+	out     zapcore.WriteSyncer
+}
+
+func (core *zapOutputCore) With(fields []zapcore.Field) zapcore.Core {
+	child := &zapOutputCore{LevelEnabler: core.LevelEnabler, encoder: core.encoder.Clone(), out: core.out}
+	for _, field := range fields {
+		field.AddTo(child.encoder)
+	}
+	return child
+}
+
+func (core *zapOutputCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if core.Enabled(entry.Level) {
+		return checked.AddCore(entry, core)
+	}
+	return checked
+}
+
+// Write writes the entry with l.WriteEntry, a failure is handled there so zap does not report it again
+func (core *zapOutputCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	level := levelOfZap(entry.Level)
+	buffer, err := core.encoder.EncodeEntry(entry, fields)
+	if err != nil {
+		l.FailEntry(level, err)
+		return nil
+	}
+	_, _ = l.WriteEntry(core.out, level, buffer.Bytes())
+	buffer.Free()
+	// the process may stop after the entry, so it is synced like zapcore.NewCore does
+	if entry.Level > zapcore.ErrorLevel {
+		l.HandleError(core.Sync())
+	}
+	return nil
+}
+
+func (core *zapOutputCore) Sync() error {
+	return core.out.Sync()
+}
+
+// zapErrorOutput reports the errors written by zap, like a failing hook, to the l.ErrorHandler
+type zapErrorOutput struct{}
+
+func (zapErrorOutput) Write(message []byte) (int, error) {
+	l.HandleError(errors.New(strings.TrimSpace(string(message))))
+	return len(message), nil
+}
+
+func (zapErrorOutput) Sync() error {
+	return nil
+}
+
+func (sinks *zapSinks) open(destination l.Destination) (zapcore.WriteSyncer, error) {
+	output, err := l.OpenDestination(destination)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't open sink %q: %v", destination.Out, err)
+	}
+	sinks.closers = append(sinks.closers, output.Close)
+	return zapLockedOutput{WriteSyncer: zapcore.Lock(output), stderr: l.IsStderr(output)}, nil
+}
+
+// zapLockedOutput serializes the writes of an output and keeps it marked as os.Stderr for l.WriteEntry
+type zapLockedOutput struct {
+	zapcore.WriteSyncer
+	stderr bool
+}
+
+func (output zapLockedOutput) Stderr() bool {
+	return output.stderr
+}
+
+// close closes the sinks once, the logger and its children share them
+func (sinks *zapSinks) close() error {
+	var errs []error
+	sinks.once.Do(func() {
+		for _, closer := range sinks.closers {
+			if err := closer(); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	})
+	return errors.Join(errs...)
+}
+
+// newZapLevelEnabler enables the levels of the destination range which are enabled by the logger level
+func newZapLevelEnabler(level zapcore.LevelEnabler, destination l.Destination) (zapcore.LevelEnabler, error) {
+	if destination.Level == "" && destination.Below == "" {
+		return level, nil
+	}
+	minLevel, belowLevel := zapTraceLevel, zapcore.FatalLevel+1
+	if destination.Level != "" {
+		destinationLevel, err := newZapLevel(destination.Level)
+		if err != nil {
+			return nil, err
+		}
+		minLevel = destinationLevel
+	}
+	if destination.Below != "" {
+		destinationBelow, err := newZapLevel(destination.Below)
+		if err != nil {
+			return nil, err
+		}
+		belowLevel = destinationBelow
+	}
+	return zap.LevelEnablerFunc(func(entryLevel zapcore.Level) bool {
+		return entryLevel >= minLevel && entryLevel < belowLevel && level.Enabled(entryLevel)
+	}), nil
+}
+
+// newZapCore creates a core for every destination of the output, each one with its level range and encoding
+func (zapOptions zapOptions) newZapCore(level zapcore.LevelEnabler, output l.Out, sinks *zapSinks) (zapcore.Core, error) {
+	destinations, err := output.Destinations()
+	if err != nil {
+		return nil, err
+	}
+	config, err := zapOptions.encoderConfig()
+	if err != nil {
+		return nil, err
+	}
+	cores := make([]zapcore.Core, 0, len(destinations))
+	for _, destination := range destinations {
+		encoding := zapOptions.encoding
+		if destination.Encoding != "" {
+			encoding = destination.Encoding
+		}
+		encoder, err := newZapEncoder(encoding, config)
+		if err != nil {
+			return nil, err
+		}
+		enabler, err := newZapLevelEnabler(level, destination)
+		if err != nil {
+			return nil, err
+		}
+		writer, err := sinks.open(destination)
+		if err != nil {
+			return nil, err
+		}
+		cores = append(cores, &zapOutputCore{LevelEnabler: enabler, encoder: encoder, out: writer})
+	}
+	return zapcore.NewTee(cores...), nil
+}
+
+// levelController is the threshold of a logger built by NewLogger, it changes the level of the zap cores
+type levelController struct {
+	level zap.AtomicLevel
+}
+
+func (controller levelController) Level() l.Level {
+	return levelOfZap(controller.level.Level())
+}
+
+func (controller levelController) SetLevel(level l.Level) error {
+	zapLevel, err := newZapLevel(level)
+	if err != nil {
+		return fmt.Errorf("err_invalid_level{Level=%q}", level)
+	}
+	controller.level.SetLevel(zapLevel)
+	return nil
+}
+
+func (controller levelController) Enabled(level l.Level) bool {
+	return level.Enabled(controller.Level())
+}
+
+// LevelOf returns the threshold of a zap logger built by NewLogger, SetLevel changes the level of the logger
+// and of its children, it is nil for a zap logger built in any other way
+func LevelOf(logger *zap.Logger) l.LevelController {
+	if logger == nil {
+		return nil
+	}
+	if core, ok := logger.Core().(zapSinksCore); ok {
+		return levelController{level: core.level}
+	}
+	return nil
+}
+
+// NewLogger creates a zap logger which writes json entries to every destination of the output,
+// the options change its encoding, keys and annotations.
+// The level is the threshold of the logger returned by LevelOf, which l.New uses for the driver of the logger
+func NewLogger(level l.Level, output l.Out, options ...Option) (*zap.Logger, error) {
+	zapLevel, errLevel := newZapLevel(level)
+	if errLevel != nil {
+		return nil, errLevel
+	}
+	zapOptions := newZapOptions(options)
+	stacktraceLevel, errStacktrace := newZapLevel(zapOptions.stacktraceLevel)
+	if errStacktrace != nil {
+		return nil, errStacktrace
+	}
+	var (
+		atomicLevel = zap.NewAtomicLevelAt(zapLevel)
+		sinks       = new(zapSinks)
+	)
+	core, errCore := zapOptions.newZapCore(atomicLevel, output, sinks)
+	if errCore != nil {
+		_ = sinks.close()
+		return nil, errCore
+	}
+	loggerOptions := []zap.Option{
+		zap.ErrorOutput(zapErrorOutput{}),
+		zap.AddStacktrace(stacktraceLevel),
+	}
+	if zapOptions.caller {
+		loggerOptions = append(loggerOptions, zap.AddCaller())
+	}
+	// the sinks core wraps the cores of the options, like hooks, so the driver can still close the sinks
+	logger := zap.New(core, append(loggerOptions, zapOptions.zapOptions...)...)
+	return logger.WithOptions(zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		return zapSinksCore{Core: core, sinks: sinks, level: atomicLevel}
+	})), nil
+}
+
+// NewZapLogger creates a zap logger which writes json entries to every destination of the output.
+//
+// Deprecated: NewZapLogger was l.NewZapLogger before the zap driver left the l package, use NewLogger
+func NewZapLogger(level l.Level, output l.Out, options ...Option) (*zap.Logger, error) {
+	return NewLogger(level, output, options...)
+}
+
+// NewLoggerDefault creates an l.Logger writing json to STDOUT with a DEBUG threshold that can be changed at runtime,
+// a failure to build it is reported to the l.ErrorHandler and the Logger writes nothing
+func NewLoggerDefault() l.Logger {
+	zapLogger, err := NewLogger(l.DEBUG, l.STDOUT)
+	l.HandleError(err)
+	return l.New(New(zapLogger))
+}
+
+// NewStdSplitLogger creates an l.Logger like NewLoggerDefault which writes ERROR and higher levels to STDERR
+// and the lower levels to STDOUT
+func NewStdSplitLogger() l.Logger {
+	zapLogger, err := NewLogger(l.DEBUG, l.NewSplitOut(l.STDOUT, l.STDERR, l.ERROR))
+	l.HandleError(err)
+	return l.New(New(zapLogger))
+}
+
+// NewZapLoggerDefault creates an l.Logger writing json to STDOUT with a DEBUG threshold that can be changed at runtime.
+//
+// Deprecated: NewZapLoggerDefault was l.NewZapLoggerDefault before the zap driver left the l package, use NewLoggerDefault
+func NewZapLoggerDefault() l.Logger {
+	return NewLoggerDefault()
+}
@@ -0,0 +1,673 @@
+package zapdriver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rjansen/l"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+type testZapLogger struct {
+	name   string
+	output l.Out
+	level  l.Level
+	err    error
+}
+
+func (scenario testZapLogger) setup(t *testing.T) {
+}
+
+func TestNewLogger(test *testing.T) {
+	scenarios := []testZapLogger{
+		{
+			name:   "Creates a new debug level zap logger instance",
+			output: l.STDOUT,
+			level:  l.DEBUG,
+		},
+		{
+			name:   "Creates a new info level zap logger instance",
+			output: l.STDOUT,
+			level:  l.INFO,
+		},
+		{
+			name:   "Creates a new error level zap logger instance",
+			output: l.STDOUT,
+			level:  l.ERROR,
+		},
+		{
+			name:   "Creates a new zap logger instance with stderr output",
+			output: l.STDERR,
+			level:  l.DEBUG,
+		},
+		{
+			name:   "Does not creates a new zap logger with invalid level",
+			output: l.STDOUT,
+			level:  l.Level("invalid"),
+			err:    errors.New("unrecognized level: \"invalid\""),
+		},
+		{
+			name:   "Does not creates a new zap logger with invalid output",
+			output: l.Out(""),
+			level:  l.DEBUG,
+			err:    errors.New("couldn't open sink \"\": open : no such file or directory"),
+		},
+	}
+
+	for index, scenario := range scenarios {
+		test.Run(
+			fmt.Sprintf("[%d]-%s", index, scenario.name),
+			func(t *testing.T) {
+				scenario.setup(t)
+
+				var logger, err = NewLogger(scenario.level, scenario.output)
+				assert.Equal(t, scenario.err, err, "error instance")
+				if scenario.err == nil {
+					assert.NotNil(t, logger, "zap.Logger instance")
+				} else {
+					assert.Nil(t, logger, "zap.Logger instance")
+				}
+			},
+		)
+	}
+}
+
+func TestNewLoggerDefault(test *testing.T) {
+	logger := NewLoggerDefault()
+	assert.NotNil(test, logger, "loggerDefault instance")
+	assert.Equal(test, l.DEBUG, logger.Level(), "loggerDefault level")
+	assert.Equal(test, l.DEBUG, NewZapLoggerDefault().Level(), "deprecated loggerDefault level")
+
+	zapLogger, err := NewZapLogger(l.INFO, l.STDOUT)
+	assert.NoError(test, err, "deprecated zap logger")
+	assert.Equal(test, l.INFO, LevelOf(zapLogger).Level(), "deprecated zap logger level")
+}
+
+func TestZapLoggerLevel(t *testing.T) {
+	dir, err := ioutil.TempDir("", "l")
+	assert.NoError(t, err, "temp dir")
+	defer os.RemoveAll(dir)
+
+	var (
+		lowPath  = filepath.Join(dir, "low.log")
+		highPath = filepath.Join(dir, "high.log")
+		output   = l.NewSplitOut(l.Out(lowPath), l.Out(highPath), l.ERROR)
+	)
+	zapLogger, err := NewLogger(l.INFO, output, WithStacktraceLevel(l.FATAL))
+	assert.NoError(t, err, "zap logger")
+	log := l.New(New(zapLogger))
+	defer log.Close(context.Background())
+
+	assert.Equal(t, l.INFO, log.Level(), "logger level")
+	assert.Equal(t, l.INFO, LevelOf(zapLogger).Level(), "zap logger level")
+	log.Debug(context.Background(), "debuglog1")
+	assert.NoError(t, log.SetLevel(l.DEBUG), "SetLevel error")
+	log.Named("api").Debug(context.Background(), "debuglog2")
+	zapLogger.Debug("debuglog3")
+	assert.NoError(t, log.SetLevel(l.ERROR), "SetLevel error")
+	log.Warn(context.Background(), "warnlog")
+	log.Error(context.Background(), "errorlog")
+	assert.EqualError(t, LevelOf(zapLogger).SetLevel(l.Level("invalid")), `err_invalid_level{Level="invalid"}`, "invalid level")
+	assert.NoError(t, log.Sync(context.Background()), "sync logger")
+
+	for path, expected := range map[string][]string{
+		lowPath:  {"debuglog2", "debuglog3"},
+		highPath: {"errorlog"},
+	} {
+		data, err := ioutil.ReadFile(path)
+		assert.NoError(t, err, "entries of %s", path)
+		var messages []string
+		for _, entry := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+			messages = append(messages, decodeZapEntry(t, entry)["message"].(string))
+		}
+		assert.Equal(t, expected, messages, "messages of %s", path)
+	}
+
+	assert.Nil(t, LevelOf(nil), "nil logger level")
+	assert.Nil(t, LevelOf(zap.NewNop()), "zap logger level")
+	assert.Equal(t, l.TRACE, l.New(New(zap.NewNop())).Level(), "zap driver level")
+}
+
+func TestZapLoggerLevelRules(t *testing.T) {
+	dir, err := ioutil.TempDir("", "l")
+	assert.NoError(t, err, "temp dir")
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "app.log")
+	zapLogger, err := NewLogger(l.INFO, l.Out(path))
+	assert.NoError(t, err, "zap logger")
+	rules, err := l.ParseLevelRules("root=info,api=debug")
+	assert.NoError(t, err, "level rules")
+	log, err := l.NewWithLevelRules(New(zapLogger), rules)
+	assert.NoError(t, err, "logger with rules")
+	defer log.Close(context.Background())
+
+	api := log.Named("api")
+	assert.Equal(t, l.INFO, log.Level(), "root level")
+	assert.True(t, api.Enabled(l.DEBUG), "api debug enabled")
+	assert.Equal(t, l.DEBUG, LevelOf(zapLogger).Level(), "zap logger level")
+	log.Debug(context.Background(), "debuglog1")
+	api.Debug(context.Background(), "debuglog2")
+	assert.NoError(t, l.LevelsOf(log).ResetLevel("api"), "ResetLevel error")
+	assert.Equal(t, l.INFO, LevelOf(zapLogger).Level(), "zap logger level after reset")
+	api.Debug(context.Background(), "debuglog3")
+	api.Info(context.Background(), "infolog")
+	assert.NoError(t, log.Sync(context.Background()), "sync logger")
+
+	data, err := ioutil.ReadFile(path)
+	assert.NoError(t, err, "entries")
+	var messages []string
+	for _, entry := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		messages = append(messages, decodeZapEntry(t, entry)["message"].(string))
+	}
+	assert.Equal(t, []string{"debuglog2", "infolog"}, messages, "messages")
+}
+
+type testZapLoggerOptions struct {
+	name    string
+	options []Option
+	level   l.Level
+	check   func(*testing.T, string)
+	err     error
+}
+
+func decodeZapEntry(t *testing.T, entry string) map[string]interface{} {
+	var fields map[string]interface{}
+	assert.NoError(t, json.Unmarshal([]byte(entry), &fields), "json entry")
+	return fields
+}
+
+func TestZapLoggerOptions(test *testing.T) {
+	scenarios := []testZapLoggerOptions{
+		{
+			name:  "Writes the default json layout",
+			level: l.INFO,
+			check: func(t *testing.T, entry string) {
+				fields := decodeZapEntry(t, entry)
+				assert.Equal(t, "info", fields["level"], "level key")
+				assert.Equal(t, "optionslog", fields["message"], "message key")
+				assert.Equal(t, "api", fields["logger"], "logger key")
+				assert.Regexp(t, `^\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}\.\d{3}`, fields["time"], "time key")
+				assert.NotContains(t, fields, "caller", "caller key")
+				assert.NotContains(t, fields, "stack", "stack key")
+			},
+		},
+		{
+			name:    "Writes renamed keys",
+			level:   l.INFO,
+			options: []Option{WithTimeKey("@timestamp"), WithLevelKey("severity"), WithMessageKey("msg"), WithNameKey("")},
+			check: func(t *testing.T, entry string) {
+				fields := decodeZapEntry(t, entry)
+				assert.Equal(t, "info", fields["severity"], "level key")
+				assert.Equal(t, "optionslog", fields["msg"], "message key")
+				assert.Contains(t, fields, "@timestamp", "time key")
+				assert.NotContains(t, fields, "logger", "logger key")
+				assert.NotContains(t, fields, "time", "default time key")
+			},
+		},
+		{
+			name:    "Writes console encoding",
+			level:   l.WARN,
+			options: []Option{WithEncoding(l.ConsoleEncoding)},
+			check: func(t *testing.T, entry string) {
+				assert.Regexp(t, `^\S+\twarn\tapi\toptionslog\t\{"key": "value"\}\n$`, entry, "console entry")
+			},
+		},
+		{
+			name:    "Writes epoch times",
+			level:   l.INFO,
+			options: []Option{WithTimeFormat(EpochTime)},
+			check: func(t *testing.T, entry string) {
+				assert.IsType(t, float64(0), decodeZapEntry(t, entry)["time"], "time key")
+			},
+		},
+		{
+			name:    "Writes RFC3339Nano times",
+			level:   l.INFO,
+			options: []Option{WithTimeFormat(RFC3339NanoTime)},
+			check: func(t *testing.T, entry string) {
+				value, _ := decodeZapEntry(t, entry)["time"].(string)
+				_, err := time.Parse(time.RFC3339Nano, value)
+				assert.NoError(t, err, "time key")
+			},
+		},
+		{
+			name:    "Writes the caller",
+			level:   l.INFO,
+			options: []Option{WithCaller(true), WithCallerKey("source")},
+			check: func(t *testing.T, entry string) {
+				assert.Contains(t, decodeZapEntry(t, entry), "source", "caller key")
+			},
+		},
+		{
+			name:    "Writes stacktraces from the warn level",
+			level:   l.WARN,
+			options: []Option{WithStacktraceLevel(l.WARN), WithStacktraceKey("stacktrace")},
+			check: func(t *testing.T, entry string) {
+				assert.Contains(t, decodeZapEntry(t, entry), "stacktrace", "stacktrace key")
+			},
+		},
+		{
+			name:    "Does not create with an invalid encoding",
+			options: []Option{WithEncoding(l.Encoding("xml"))},
+			err:     errors.New("err_invalid_encoding{Encoding=\"xml\"}"),
+		},
+		{
+			name:    "Does not create with an invalid time format",
+			options: []Option{WithTimeFormat(TimeFormat("unix"))},
+			err:     errors.New("err_invalid_time_format{TimeFormat=\"unix\"}"),
+		},
+		{
+			name:    "Does not create with an invalid stacktrace level",
+			options: []Option{WithStacktraceLevel(l.Level("invalid"))},
+			err:     errors.New("unrecognized level: \"invalid\""),
+		},
+	}
+
+	for index, scenario := range scenarios {
+		test.Run(
+			fmt.Sprintf("[%d]-%s", index, scenario.name),
+			func(t *testing.T) {
+				dir, err := ioutil.TempDir("", "l")
+				assert.NoError(t, err, "temp dir")
+				defer os.RemoveAll(dir)
+				path := filepath.Join(dir, "app.log")
+
+				zapLogger, err := NewLogger(l.DEBUG, l.Out(path), scenario.options...)
+				assert.Equal(t, scenario.err, err, "error instance")
+				if scenario.err != nil {
+					assert.Nil(t, zapLogger, "zap.Logger instance")
+					return
+				}
+				log := l.New(New(zapLogger)).Named("api").With(l.String("key", "value"))
+				if scenario.level == l.WARN {
+					log.Warn(context.Background(), "optionslog")
+				} else {
+					log.Info(context.Background(), "optionslog")
+				}
+				assert.NoError(t, log.Close(context.Background()), "close logger")
+
+				data, err := ioutil.ReadFile(path)
+				assert.NoError(t, err, "log file")
+				scenario.check(t, string(data))
+			},
+		)
+	}
+}
+
+func TestZapLoggerDestinations(t *testing.T) {
+	dir, err := ioutil.TempDir("", "l")
+	assert.NoError(t, err, "temp dir")
+	defer os.RemoveAll(dir)
+
+	var (
+		consolePath = filepath.Join(dir, "console.log")
+		jsonPath    = filepath.Join(dir, "app.log")
+		output      = l.NewOut(
+			l.Destination{Out: l.Out(consolePath), Encoding: l.ConsoleEncoding},
+			l.Destination{Out: l.Out("file://" + jsonPath), Level: l.WARN},
+		)
+	)
+	zapLogger, err := NewLogger(l.DEBUG, output, WithStacktraceLevel(l.FATAL))
+	assert.NoError(t, err, "zap logger")
+
+	log := l.New(New(zapLogger)).Named("api")
+	log.Trace(context.Background(), "tracelog")
+	log.Info(context.Background(), "infolog")
+	log.Error(context.Background(), "errorlog", l.String("key", "value"))
+	assert.NoError(t, log.Close(context.Background()), "close logger")
+
+	consoleData, err := ioutil.ReadFile(consolePath)
+	assert.NoError(t, err, "console entries")
+	consoleEntries := strings.Split(strings.TrimSpace(string(consoleData)), "\n")
+	assert.Len(t, consoleEntries, 2, "console entries")
+	assert.Regexp(t, `^\S+\tinfo\tapi\tinfolog$`, consoleEntries[0], "console info entry")
+	assert.Regexp(t, `^\S+\terror\tapi\terrorlog\t\{"key": "value"\}`, consoleEntries[1], "console error entry")
+
+	jsonData, err := ioutil.ReadFile(jsonPath)
+	assert.NoError(t, err, "json entries")
+	jsonEntries := strings.Split(strings.TrimSpace(string(jsonData)), "\n")
+	assert.Len(t, jsonEntries, 1, "json entries")
+	fields := decodeZapEntry(t, jsonEntries[0])
+	assert.Equal(t, "error", fields["level"], "json level")
+	assert.Equal(t, "value", fields["key"], "json field")
+}
+
+func TestZapSinksStderr(t *testing.T) {
+	sinks := new(zapSinks)
+	stderr, err := sinks.open(l.Destination{Out: l.STDERR})
+	assert.NoError(t, err, "open stderr")
+	assert.True(t, l.IsStderr(stderr), "stderr marked sink")
+	stdout, err := sinks.open(l.Destination{Out: l.STDOUT})
+	assert.NoError(t, err, "open stdout")
+	assert.False(t, l.IsStderr(stdout), "stdout marked sink")
+	assert.NoError(t, sinks.close(), "close sinks")
+}
+
+func TestZapLoggerDestinationsError(test *testing.T) {
+	scenarios := []struct {
+		name   string
+		output l.Out
+		err    error
+	}{
+		{
+			name:   "Does not create with an invalid destination",
+			output: l.Out("stdout,/invalid/path/app.log"),
+			err:    errors.New("couldn't open sink \"/invalid/path/app.log\": open /invalid/path/app.log: no such file or directory"),
+		},
+		{
+			name:   "Does not create with an invalid destination level",
+			output: l.Out("stdout?level=invalid"),
+			err:    errors.New("err_invalid_out{Out=\"stdout?level=invalid\", Message='invalid level'}"),
+		},
+	}
+
+	for index, scenario := range scenarios {
+		test.Run(
+			fmt.Sprintf("[%d]-%s", index, scenario.name),
+			func(t *testing.T) {
+				zapLogger, err := NewLogger(l.DEBUG, scenario.output)
+				assert.Equal(t, scenario.err, err, "error instance")
+				assert.Nil(t, zapLogger, "zap.Logger instance")
+			},
+		)
+	}
+}
+
+func TestZapLoggerSplit(t *testing.T) {
+	dir, err := ioutil.TempDir("", "l")
+	assert.NoError(t, err, "temp dir")
+	defer os.RemoveAll(dir)
+
+	var (
+		lowPath  = filepath.Join(dir, "low.log")
+		highPath = filepath.Join(dir, "high.log")
+		output   l.Out
+	)
+	assert.NoError(t, output.Set(fmt.Sprintf("%s?below=error,%s?level=error", lowPath, highPath)), "split out")
+	zapLogger, err := NewLogger(l.DEBUG, output, WithStacktraceLevel(l.FATAL))
+	assert.NoError(t, err, "zap logger")
+
+	log := l.New(New(zapLogger))
+	log.Trace(context.Background(), "tracelog")
+	log.Debug(context.Background(), "debuglog")
+	log.Warn(context.Background(), "warnlog")
+	log.Error(context.Background(), "errorlog")
+	assert.NoError(t, log.Close(context.Background()), "close logger")
+
+	for path, expected := range map[string][]string{
+		lowPath:  {"debuglog", "warnlog"},
+		highPath: {"errorlog"},
+	} {
+		data, err := ioutil.ReadFile(path)
+		assert.NoError(t, err, "entries of %s", path)
+		var messages []string
+		for _, entry := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+			messages = append(messages, decodeZapEntry(t, entry)["message"].(string))
+		}
+		assert.Equal(t, expected, messages, "messages of %s", path)
+	}
+}
+
+func TestStdSplitLogger(t *testing.T) {
+	dir, err := ioutil.TempDir("", "l")
+	assert.NoError(t, err, "temp dir")
+	defer os.RemoveAll(dir)
+
+	stdout, err := os.Create(filepath.Join(dir, "stdout"))
+	assert.NoError(t, err, "stdout file")
+	stderr, err := os.Create(filepath.Join(dir, "stderr"))
+	assert.NoError(t, err, "stderr file")
+	defaultStdout, defaultStderr := os.Stdout, os.Stderr
+	os.Stdout, os.Stderr = stdout, stderr
+	log := NewStdSplitLogger()
+	os.Stdout, os.Stderr = defaultStdout, defaultStderr
+
+	assert.Equal(t, l.DEBUG, log.Level(), "split logger level")
+	log.Trace(context.Background(), "tracelog")
+	log.Info(context.Background(), "infolog")
+	log.Error(context.Background(), "errorlog")
+	log.Warn(context.Background(), "warnlog")
+	assert.NoError(t, stdout.Close(), "stdout close")
+	assert.NoError(t, stderr.Close(), "stderr close")
+
+	for file, expected := range map[*os.File][]string{
+		stdout: {"infolog", "warnlog"},
+		stderr: {"errorlog"},
+	} {
+		data, err := ioutil.ReadFile(file.Name())
+		assert.NoError(t, err, "entries of %s", file.Name())
+		var messages []string
+		for _, entry := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+			messages = append(messages, decodeZapEntry(t, entry)["message"].(string))
+		}
+		assert.Equal(t, expected, messages, "messages of %s", file.Name())
+	}
+}
+
+func TestZapLoggerRotation(t *testing.T) {
+	dir, err := ioutil.TempDir("", "l")
+	assert.NoError(t, err, "temp dir")
+	defer os.RemoveAll(dir)
+
+	var output l.Out
+	assert.NoError(t, output.Set("file://"+filepath.Join(dir, "app.log")+"?maxsize=1KB&maxbackups=2&compress=gzip"), "rotating out")
+	zapLogger, err := NewLogger(l.DEBUG, output)
+	assert.NoError(t, err, "zap logger")
+
+	log := l.New(New(zapLogger))
+	for index := 0; index < 100; index++ {
+		log.Info(context.Background(), "infolog", l.Int("index", index), l.String("padding", strings.Repeat("p", 64)))
+	}
+	// close waits the backups compressed in background
+	assert.NoError(t, log.Close(context.Background()), "close logger")
+
+	infos, err := ioutil.ReadDir(dir)
+	assert.NoError(t, err, "log files")
+	var current, compressed, other int
+	for _, info := range infos {
+		switch {
+		case info.Name() == "app.log":
+			current++
+			assert.True(t, info.Size() <= 1024, "current file size %d", info.Size())
+		case strings.HasSuffix(info.Name(), ".log.gz"):
+			compressed++
+		default:
+			other++
+		}
+	}
+	assert.Equal(t, 1, current, "current files")
+	assert.Equal(t, 2, compressed, "compressed backups")
+	assert.Equal(t, 0, other, "other files")
+}
+
+func TestZapLoggerClose(t *testing.T) {
+	dir, err := ioutil.TempDir("", "l")
+	assert.NoError(t, err, "temp dir")
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "app.log")
+	zapLogger, err := NewLogger(l.DEBUG, l.NewOut(l.Destination{Out: l.STDOUT, Level: l.FATAL}, l.Destination{Out: l.Out("file://" + path)}))
+	assert.NoError(t, err, "zap logger")
+	log := l.New(New(zapLogger)).Named("api")
+
+	log.Info(context.Background(), "infolog")
+	assert.NoError(t, log.Sync(context.Background()), "sync logger")
+	assert.NoError(t, log.Close(context.Background()), "close logger")
+	assert.NoError(t, log.Close(context.Background()), "close closed logger")
+
+	data, err := ioutil.ReadFile(path)
+	assert.NoError(t, err, "file entries")
+	assert.Equal(t, "infolog", decodeZapEntry(t, string(data))["message"], "file message")
+
+	// a closed file is not opened again by Reopen
+	assert.NoError(t, os.Remove(path), "remove file")
+	_ = l.Reopen()
+	_, err = os.Stat(path)
+	assert.True(t, os.IsNotExist(err), "reopened file")
+}
+
+// testErrors records the errors reported to the l.ErrorHandler
+type testErrors struct {
+	mutex  sync.Mutex
+	errors []error
+}
+
+func (handler *testErrors) handle(err error) {
+	handler.mutex.Lock()
+	handler.errors = append(handler.errors, err)
+	handler.mutex.Unlock()
+}
+
+func (handler *testErrors) Messages() []string {
+	handler.mutex.Lock()
+	defer handler.mutex.Unlock()
+	var messages []string
+	for _, err := range handler.errors {
+		messages = append(messages, err.Error())
+	}
+	return messages
+}
+
+func captureErrors() (*testErrors, func()) {
+	handler := new(testErrors)
+	l.SetErrorHandler(handler.handle)
+	return handler, func() { l.SetErrorHandler(nil) }
+}
+
+// captureStderr replaces os.Stderr with a file and returns a function restoring it which returns the file content
+func captureStderr(t *testing.T) func() string {
+	file, err := ioutil.TempFile("", "stderr")
+	assert.NoError(t, err, "stderr file")
+	stderr := os.Stderr
+	os.Stderr = file
+	return func() string {
+		os.Stderr = stderr
+		assert.NoError(t, file.Close(), "close stderr file")
+		defer os.Remove(file.Name())
+		data, err := ioutil.ReadFile(file.Name())
+		assert.NoError(t, err, "stderr content")
+		return string(data)
+	}
+}
+
+// testFailingWriter fails every write
+type testFailingWriter struct {
+	err error
+}
+
+func (writer testFailingWriter) Write([]byte) (int, error) {
+	return 0, writer.err
+}
+
+func (writer testFailingWriter) Sync() error {
+	return nil
+}
+
+func statsDelta(before l.Statistics, after l.Statistics) l.Statistics {
+	delta := make(l.Statistics, len(after))
+	for level, stats := range after {
+		delta[level] = l.LevelStats{
+			Written: stats.Written - before[level].Written,
+			Dropped: stats.Dropped - before[level].Dropped,
+			Failed:  stats.Failed - before[level].Failed,
+			Bytes:   stats.Bytes - before[level].Bytes,
+		}
+	}
+	return delta
+}
+
+func TestZapLoggerStats(t *testing.T) {
+	dir, err := ioutil.TempDir("", "l")
+	assert.NoError(t, err, "temp dir")
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "app.log")
+	zapLogger, err := NewLogger(l.DEBUG, l.Out("file://"+path))
+	assert.NoError(t, err, "zap logger")
+	log := l.New(New(zapLogger))
+
+	before := l.Stats()
+	log.Trace(context.Background(), "tracelog")
+	log.Info(context.Background(), "infolog")
+	log.Warn(context.Background(), "warnlog")
+	log.Info(context.Background(), "infolog")
+	assert.NoError(t, log.Close(context.Background()), "close logger")
+	delta := statsDelta(before, l.Stats())
+
+	info, err := os.Stat(path)
+	assert.NoError(t, err, "file info")
+	assert.Equal(t, uint64(2), delta[l.INFO].Written, "info entries")
+	assert.Equal(t, uint64(1), delta[l.WARN].Written, "warn entries")
+	assert.Equal(t, uint64(0), delta[l.TRACE].Written, "trace entries")
+	assert.Equal(t, uint64(info.Size()), delta.Total().Bytes, "bytes")
+}
+
+func TestZapLoggerErrors(t *testing.T) {
+	handler, reset := captureErrors()
+	defer reset()
+
+	// a failing output is reported and its entries are written to stderr
+	stderr := captureStderr(t)
+	core := &zapOutputCore{
+		LevelEnabler: zapcore.DebugLevel,
+		encoder:      zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig()),
+		out:          testFailingWriter{err: errors.New("disk full")},
+	}
+	before := l.Stats()
+	l.New(New(zap.New(core))).With(l.String("key", "value")).Error(context.Background(), "errorlog")
+	delta := statsDelta(before, l.Stats())
+	assert.Contains(t, stderr(), `"msg":"errorlog","key":"value"`, "stderr entry")
+	assert.Equal(t, uint64(1), delta[l.ERROR].Failed, "failed entries")
+	assert.Equal(t, []string{`err_write{Level="error", Message='disk full'}`}, handler.Messages(), "write errors")
+
+	// the errors of zap, like a failing hook, are reported too
+	dir, err := ioutil.TempDir("", "l")
+	assert.NoError(t, err, "temp dir")
+	defer os.RemoveAll(dir)
+	zapLogger, err := NewLogger(l.DEBUG, l.Out(filepath.Join(dir, "app.log")), WithZapOptions(zap.Hooks(func(zapcore.Entry) error {
+		return errors.New("err_hook")
+	})))
+	assert.NoError(t, err, "zap logger")
+	log := l.New(New(zapLogger))
+	log.Info(context.Background(), "infolog")
+	assert.NoError(t, log.Close(context.Background()), "close logger")
+	messages := handler.Messages()
+	assert.Len(t, messages, 2, "handled errors")
+	assert.True(t, strings.HasSuffix(messages[1], "err_hook"), "hook error %s", messages[1])
+}
+
+func TestZapLoggerReopen(t *testing.T) {
+	dir, err := ioutil.TempDir("", "reopen")
+	assert.NoError(t, err, "temp dir")
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "app.log")
+	zapLogger, err := NewLogger(l.DEBUG, l.Out("file://"+path))
+	assert.NoError(t, err, "zap logger")
+	log := l.New(New(zapLogger))
+	defer log.Close(context.Background())
+
+	log.Info(context.Background(), "infolog1")
+	assert.NoError(t, os.Rename(path, path+".1"), "move file")
+	assert.NoError(t, l.Reopen(), "reopen files")
+	log.Info(context.Background(), "infolog2")
+	_ = zapLogger.Sync()
+
+	for name, expected := range map[string]string{"app.log.1": "infolog1", "app.log": "infolog2"} {
+		data, err := ioutil.ReadFile(filepath.Join(dir, name))
+		assert.NoError(t, err, "read %s", name)
+		assert.Equal(t, expected, decodeZapEntry(t, string(data))["message"], "message of %s", name)
+	}
+}
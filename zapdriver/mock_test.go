@@ -1,4 +1,4 @@
-package l
+package zapdriver
 
 import (
 	"github.com/stretchr/testify/mock"
@@ -23,11 +23,26 @@ func (mock *mockZapLogger) Check(level zapcore.Level, msg string) zapWriter {
 	return result.(zapWriter)
 }
 
+func (mock *mockZapLogger) With(fields ...zap.Field) zapLogger {
+	args := mock.Called(fields)
+	return args.Get(0).(zapLogger)
+}
+
+func (mock *mockZapLogger) Named(name string) zapLogger {
+	args := mock.Called(name)
+	return args.Get(0).(zapLogger)
+}
+
 func (mock *mockZapLogger) Sync() error {
 	args := mock.Called()
 	return args.Error(0)
 }
 
+func (mock *mockZapLogger) Close() error {
+	args := mock.Called()
+	return args.Error(0)
+}
+
 type mockZapWriter struct {
 	mock.Mock
 }